@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"doc-watcher/cmd"
+	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/embeddings/cache"
+	"doc-watcher/internal/embeddings/openai"
+	"doc-watcher/internal/embeddings/sovavec"
+	"doc-watcher/internal/events"
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/ocr/native"
+	"doc-watcher/internal/ocr/pages"
+	"doc-watcher/internal/ocr/passthrough"
+	"doc-watcher/internal/ocr/router"
+	"doc-watcher/internal/ocr/sovaocr"
+	"doc-watcher/internal/ocr/tesseract"
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/searcher"
+	"doc-watcher/internal/server"
+	"doc-watcher/internal/server/httpserv"
+	"doc-watcher/internal/status"
+	"doc-watcher/internal/status/boltstore"
+	"doc-watcher/internal/storage"
+	"doc-watcher/internal/watcher"
+	"doc-watcher/internal/watcher/localfs"
+	"doc-watcher/internal/watcher/minio"
+	"doc-watcher/internal/webhooks"
+)
+
+// shutdownTimeout limits waiting for services to be stopped.
+const shutdownTimeout = 60 * time.Second
+
+func main() {
+	servConfig := cmd.Execute()
+
+	storeService := storage.New(&servConfig.Storage)
+	statusService := boltstore.New(storeService)
+	eventsBroker := events.New()
+	webhookService := webhooks.New(&servConfig.Webhooks, eventsBroker, storeService)
+	recognizers := map[string]*ocr.Service{
+		sovaocr.RecognizerName:     pages.New(&servConfig.Ocr, sovaocr.New(&servConfig.Ocr)),
+		tesseract.RecognizerName:   pages.New(&servConfig.Ocr, tesseract.New(&servConfig.Ocr)),
+		passthrough.RecognizerName: passthrough.New(),
+	}
+	fallbackRecognizer, ok := recognizers[servConfig.Ocr.FallbackRecognizer]
+	if !ok {
+		log.Fatalln("unknown fallback recognizer: ", servConfig.Ocr.FallbackRecognizer)
+	}
+	recognizers[native.RecognizerName] = native.New(&servConfig.Ocr, fallbackRecognizer)
+	ocrService := router.New(&servConfig.Ocr, recognizers)
+	searchService := searcher.New(&servConfig.Searcher)
+	embedCache := cache.New(&servConfig.Embeddings.Cache, storeService)
+	embedBackends := map[string]func(*embeddings.Config, *cache.Service) *embeddings.Service{
+		sovavec.BackendName: sovavec.New,
+		openai.BackendName:  openai.New,
+	}
+	newEmbeddings, ok := embedBackends[servConfig.Embeddings.Backend]
+	if !ok {
+		log.Fatalln("unknown embeddings backend: ", servConfig.Embeddings.Backend)
+	}
+	embedService := newEmbeddings(&servConfig.Embeddings, embedCache)
+	watchBackends := map[string]func(
+		*watcher.Config,
+		*pipeline.Config,
+		*ocr.Service,
+		*searcher.Service,
+		*embeddings.Service,
+		*status.Service,
+		*events.Broker,
+		*storage.Service,
+	) *watcher.Service{
+		minio.BackendName:   minio.New,
+		localfs.BackendName: localfs.New,
+	}
+	newWatcher, ok := watchBackends[servConfig.Watcher.Backend]
+	if !ok {
+		log.Fatalln("unknown watcher backend: ", servConfig.Watcher.Backend)
+	}
+	watchService := newWatcher(
+		&servConfig.Watcher,
+		&servConfig.Pipeline,
+		ocrService,
+		searchService,
+		embedService,
+		statusService,
+		eventsBroker,
+		storeService,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go awaitSystemSignals(cancel)
+
+	httpServer := httpserv.New(&servConfig.Server, watchService, eventsBroker, webhookService, embedCache)
+	go func() {
+		if err := httpServer.Server.Start(ctx); err != nil {
+			log.Printf("failed to start server: %v", err)
+		}
+	}()
+
+	webhooksDone := make(chan struct{})
+	go func() {
+		defer close(webhooksDone)
+		webhookService.Run(ctx)
+	}()
+	if err := watchService.Watcher.RunWatchers(ctx); err != nil {
+		log.Fatalln("failed to run watchers: ", err)
+	}
+
+	<-ctx.Done()
+	cancel()
+
+	// Watchers are terminated after pipeline workers have been stopped,
+	// so storage is closed when there are no jobs writing to it.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	shutdownServices(shutdownCtx, httpServer, watchService)
+	<-webhooksDone
+	if err := storeService.Close(); err != nil {
+		log.Println(err)
+	}
+}
+
+func awaitSystemSignals(cancel context.CancelFunc) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	<-ch
+	cancel()
+}
+
+func shutdownServices(ctx context.Context, httpServ *server.Server, watchServ *watcher.Service) {
+	if err := watchServ.Watcher.TerminateWatchers(ctx); err != nil {
+		log.Println(err)
+	}
+	if err := httpServ.Server.Shutdown(ctx); err != nil {
+		log.Println(err)
+	}
+}
@@ -15,6 +15,70 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/embeddings/cache": {
+            "get": {
+                "description": "Load cached vectors count and cache hits since start",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "embeddings"
+                ],
+                "summary": "Fetch embeddings cache stats",
+                "operationId": "embeddings-cache-stats",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/embeddings.CacheStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete all cached vectors and reset cache stats",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "embeddings"
+                ],
+                "summary": "Purge embeddings cache",
+                "operationId": "embeddings-cache-purge",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
         "/watcher/attach": {
             "put": {
                 "description": "Attach new directory to watcher",
@@ -31,13 +95,555 @@ const docTemplate = `{
                 "operationId": "folders-attach",
                 "parameters": [
                     {
-                        "description": "File entity",
-                        "name": "jsonQuery",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/httpserv.AttachDirectoryForm"
-                        }
+                        "description": "File entity",
+                        "name": "jsonQuery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.AttachDirectoryForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/backfill": {
+            "get": {
+                "description": "Load progress of launched directories backfill",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Fetch backfill progress",
+                "operationId": "fetch-backfill",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/watcher.BackfillProgress"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/documents/{bucket}/{key}": {
+            "get": {
+                "description": "Load current stage, stages timeline and processing details of document",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Fetch document processing status",
+                "operationId": "fetch-document-status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder id",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Document path into folder",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object version, the latest processed version if empty",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/watcher.DocumentStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "404": {
+                        "description": "Document not found",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/events": {
+            "get": {
+                "description": "Push Server-Sent Event each time document changes processing stage",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Stream processing events",
+                "operationId": "watcher-events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder id to filter events",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name or path to filter events",
+                        "name": "file",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/watcher.StageEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/failed": {
+            "get": {
+                "description": "Load documents which have been permanently failed while processing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Fetch failed documents",
+                "operationId": "fetch-failed",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/watcher.FailedDocument"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/failed/clean": {
+            "post": {
+                "description": "Remove failed documents from list. All documents are removed if all flag is set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Clean failed documents",
+                "operationId": "clean-failed",
+                "parameters": [
+                    {
+                        "description": "Job ids of failed documents to clean",
+                        "name": "jsonQuery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.FailedDocumentsList"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/failed/requeue": {
+            "post": {
+                "description": "Push failed documents back to processing queue. All documents are requeued if all flag is set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Requeue failed documents",
+                "operationId": "requeue-failed",
+                "parameters": [
+                    {
+                        "description": "Job ids of failed documents to requeue",
+                        "name": "jsonQuery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.FailedDocumentsList"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/processing/clean": {
+            "post": {
+                "description": "Clean processing documents",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Clean processing documents",
+                "operationId": "clean-documents",
+                "parameters": [
+                    {
+                        "description": "File names to clean processing status",
+                        "name": "jsonQuery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.FetchDocumentsList"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/processing/fetch": {
+            "post": {
+                "description": "Load processing/unrecognized/done documents by names list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Fetch processing documents",
+                "operationId": "fetch-documents",
+                "parameters": [
+                    {
+                        "description": "File names to fetch processing status",
+                        "name": "jsonQuery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.FetchDocumentsList"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/watcher.ProcessingDocuments"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/queue": {
+            "get": {
+                "description": "Load processing queue depth and count of jobs in flight by stages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Fetch processing queue stats",
+                "operationId": "fetch-queue",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/watcher.QueueStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/reembed": {
+            "get": {
+                "description": "Load progress of launched directories reembedding",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Fetch reembedding progress",
+                "operationId": "fetch-reembed",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/watcher.ReembedProgress"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/run": {
+            "get": {
+                "description": "Run all watchers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Run all watchers",
+                "operationId": "watcher-run",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/stop": {
+            "get": {
+                "description": "Stop all watchers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Stop all watchers",
+                "operationId": "watcher-stop",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ResponseForm"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request message",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
+                    "503": {
+                        "description": "Server does not available",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.ServerErrorForm"
+                        }
+                    }
+                }
+            }
+        },
+        "/watcher/{bucket}/backfill": {
+            "post": {
+                "description": "Process all existing files of directory which have not been indexed yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watcher"
+                ],
+                "summary": "Backfill directory",
+                "operationId": "folders-backfill",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder id",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -62,26 +668,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/watcher/processing/clean": {
-            "post": {
-                "description": "Clean processing documents",
+        "/watcher/{bucket}/detach": {
+            "delete": {
+                "description": "Attach new directory to watcher",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "watcher"
                 ],
-                "summary": "Clean processing documents",
-                "operationId": "clean-documents",
+                "summary": "Attach new directory to watcher",
+                "operationId": "folders-detach",
                 "parameters": [
                     {
-                        "description": "File names to clean processing status",
-                        "name": "jsonQuery",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/httpserv.FetchDocumentsList"
-                        }
+                        "type": "string",
+                        "description": "Folder ids",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -106,9 +713,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/watcher/processing/fetch": {
+        "/watcher/{bucket}/reembed": {
             "post": {
-                "description": "Load processing/unrecognized/done documents by names list",
+                "description": "Compute vectors of indexed files by current embeddings model\nand store them into the same or target index",
                 "consumes": [
                     "application/json"
                 ],
@@ -118,16 +725,23 @@ const docTemplate = `{
                 "tags": [
                     "watcher"
                 ],
-                "summary": "Fetch processing documents",
-                "operationId": "fetch-documents",
+                "summary": "Reembed directory",
+                "operationId": "folders-reembed",
                 "parameters": [
                     {
-                        "description": "File names to fetch processing status",
+                        "type": "string",
+                        "description": "Folder id",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reembedding options",
                         "name": "jsonQuery",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/httpserv.FetchDocumentsList"
+                            "$ref": "#/definitions/httpserv.ReembedForm"
                         }
                     }
                 ],
@@ -135,7 +749,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Ok",
                         "schema": {
-                            "$ref": "#/definitions/watcher.ProcessingDocuments"
+                            "$ref": "#/definitions/httpserv.ResponseForm"
                         }
                     },
                     "400": {
@@ -153,22 +767,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/watcher/run": {
+        "/webhooks": {
             "get": {
-                "description": "Run all watchers",
+                "description": "Load all webhook subscriptions without signing secrets",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watcher"
+                    "webhooks"
                 ],
-                "summary": "Run all watchers",
-                "operationId": "watcher-run",
+                "summary": "Fetch webhooks",
+                "operationId": "webhooks-fetch",
                 "responses": {
                     "200": {
                         "description": "Ok",
                         "schema": {
-                            "$ref": "#/definitions/httpserv.ResponseForm"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Subscription"
+                            }
                         }
                     },
                     "400": {
@@ -184,24 +801,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/watcher/stop": {
-            "get": {
-                "description": "Stop all watchers",
+            },
+            "put": {
+                "description": "Subscribe url to finished or failed documents events",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watcher"
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "operationId": "webhooks-create",
+                "parameters": [
+                    {
+                        "description": "Webhook subscription",
+                        "name": "jsonQuery",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.WebhookForm"
+                        }
+                    }
                 ],
-                "summary": "Stop all watchers",
-                "operationId": "watcher-stop",
                 "responses": {
                     "200": {
                         "description": "Ok",
                         "schema": {
-                            "$ref": "#/definitions/httpserv.ResponseForm"
+                            "$ref": "#/definitions/webhooks.Subscription"
                         }
                     },
                     "400": {
@@ -219,25 +848,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/watcher/{bucket}/detach": {
+        "/webhooks/{id}": {
             "delete": {
-                "description": "Attach new directory to watcher",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete webhook subscription by id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watcher"
+                    "webhooks"
                 ],
-                "summary": "Attach new directory to watcher",
-                "operationId": "folders-detach",
+                "summary": "Delete webhook",
+                "operationId": "webhooks-delete",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Folder ids",
-                        "name": "bucket",
+                        "description": "Webhook id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -255,6 +881,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpserv.BadRequestForm"
                         }
                     },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/httpserv.BadRequestForm"
+                        }
+                    },
                     "503": {
                         "description": "Server does not available",
                         "schema": {
@@ -266,6 +898,29 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "embeddings.CacheStats": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "max_entries": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
         "httpserv.AttachDirectoryForm": {
             "type": "object",
             "properties": {
@@ -288,6 +943,24 @@ const docTemplate = `{
                 }
             }
         },
+        "httpserv.FailedDocumentsList": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "job_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "886f7e11-874f-4c4e-b5e6-6e3a2e4f6c2a"
+                    ]
+                }
+            }
+        },
         "httpserv.FetchDocumentsList": {
             "type": "object",
             "properties": {
@@ -302,6 +975,27 @@ const docTemplate = `{
                 }
             }
         },
+        "httpserv.ReembedForm": {
+            "type": "object",
+            "properties": {
+                "force": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "max_pending": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "rate": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "target_folder": {
+                    "type": "string",
+                    "example": "test-folder-v2"
+                }
+            }
+        },
         "httpserv.ResponseForm": {
             "type": "object",
             "properties": {
@@ -328,6 +1022,149 @@ const docTemplate = `{
                 }
             }
         },
+        "httpserv.WebhookForm": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "test-folder"
+                    ]
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "done",
+                        "failed"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "signing-secret"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/hooks/documents"
+                }
+            }
+        },
+        "watcher.BackfillProgress": {
+            "type": "object",
+            "properties": {
+                "directory": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "listed": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "watcher.DocumentStatus": {
+            "type": "object",
+            "properties": {
+                "chunks_count": {
+                    "type": "integer"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "document_name": {
+                    "type": "string"
+                },
+                "document_path": {
+                    "type": "string"
+                },
+                "document_ssdeep": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "folder_id": {
+                    "type": "string"
+                },
+                "low_quality": {
+                    "type": "boolean"
+                },
+                "pages_count": {
+                    "type": "integer"
+                },
+                "quality": {
+                    "type": "integer"
+                },
+                "recognizer": {
+                    "type": "string"
+                },
+                "stage": {
+                    "$ref": "#/definitions/watcher.Stage"
+                },
+                "transitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/watcher.StageTransition"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "watcher.FailedDocument": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "document_name": {
+                    "type": "string"
+                },
+                "document_path": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "folder_id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "stage": {
+                    "$ref": "#/definitions/watcher.Stage"
+                }
+            }
+        },
         "watcher.ProcessingDocuments": {
             "type": "object",
             "properties": {
@@ -350,6 +1187,154 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "watcher.QueueStats": {
+            "type": "object",
+            "properties": {
+                "active_jobs": {
+                    "type": "integer"
+                },
+                "queue_depth": {
+                    "type": "integer"
+                },
+                "queue_size": {
+                    "type": "integer"
+                },
+                "stages_in_flight": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "workers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "watcher.ReembedProgress": {
+            "type": "object",
+            "properties": {
+                "directory": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "listed": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "target_folder": {
+                    "type": "string"
+                }
+            }
+        },
+        "watcher.Stage": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "downloading",
+                "ocr",
+                "embedding",
+                "storing",
+                "done",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StageQueued",
+                "StageDownloading",
+                "StageOcr",
+                "StageEmbedding",
+                "StageStoring",
+                "StageDone",
+                "StageFailed"
+            ]
+        },
+        "watcher.StageEvent": {
+            "type": "object",
+            "properties": {
+                "document_name": {
+                    "type": "string"
+                },
+                "document_path": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "folder_id": {
+                    "type": "string"
+                },
+                "stage": {
+                    "$ref": "#/definitions/watcher.Stage"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "watcher.StageTransition": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "stage": {
+                    "$ref": "#/definitions/watcher.Stage"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.Subscription": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/watcher.Stage"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -10,9 +11,13 @@ import (
 
 	"doc-watcher/internal/embeddings"
 	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/retry"
 	"doc-watcher/internal/searcher"
 	"doc-watcher/internal/server"
+	"doc-watcher/internal/storage"
 	"doc-watcher/internal/watcher"
+	"doc-watcher/internal/webhooks"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
@@ -23,6 +28,9 @@ type Config struct {
 	Server     server.Config
 	Embeddings embeddings.Config
 	Watcher    watcher.Config
+	Storage    storage.Config
+	Pipeline   pipeline.Config
+	Webhooks   webhooks.Config
 }
 
 func FromFile(filePath string) (*Config, error) {
@@ -38,24 +46,85 @@ func FromFile(filePath string) (*Config, error) {
 	viperInstance.SetDefault("ocr.Address", "ocr:8004")
 	viperInstance.SetDefault("ocr.EnableSSL", false)
 	viperInstance.SetDefault("ocr.Timeout", 300)
+	viperInstance.SetDefault("ocr.DefaultRecognizer", "native")
+	viperInstance.SetDefault("ocr.FallbackRecognizer", "sova")
+	viperInstance.SetDefault("ocr.QualityThreshold", 5000)
+	viperInstance.SetDefault("ocr.Async.Enabled", false)
+	viperInstance.SetDefault("ocr.Async.PollInterval", 1000)
+	viperInstance.SetDefault("ocr.Async.MaxPollInterval", 30000)
+	viperInstance.SetDefault("ocr.Async.Multiplier", 1.5)
+	viperInstance.SetDefault("ocr.Async.JobTimeout", 3600)
+	viperInstance.SetDefault("ocr.Pages.Enabled", false)
+	viperInstance.SetDefault("ocr.Pages.Concurrency", 4)
+	viperInstance.SetDefault("ocr.Pages.Dpi", 300)
+	viperInstance.SetDefault("ocr.Pages.PdfCommand", "pdftoppm")
+	viperInstance.SetDefault("ocr.Pages.TiffCommand", "tiffsplit")
+	viperInstance.SetDefault("ocr.Tesseract.Command", "tesseract")
+	viperInstance.SetDefault("ocr.Tesseract.PdfCommand", "pdftoppm")
+	viperInstance.SetDefault("ocr.Tesseract.Languages", []string{"rus", "eng"})
+	viperInstance.SetDefault("ocr.Tesseract.Dpi", 300)
+	viperInstance.SetDefault("ocr.Tesseract.Timeout", 600)
+	viperInstance.SetDefault("ocr.Native.MaxDecodedSize", 64)
 
 	viperInstance.SetDefault("searcher.Address", "doc-searcher:2892")
 	viperInstance.SetDefault("searcher.EnableSSL", false)
+	viperInstance.SetDefault("searcher.StorageMode", "combined")
+	viperInstance.SetDefault("searcher.Concurrency", 4)
 
+	viperInstance.SetDefault("embeddings.Backend", "sova")
+	viperInstance.SetDefault("embeddings.Model", "")
 	viperInstance.SetDefault("embeddings.Address", "embeddings:8001")
 	viperInstance.SetDefault("embeddings.EnableSSL", false)
 	viperInstance.SetDefault("embeddings.ChunkSize", 800)
+	viperInstance.SetDefault("embeddings.ChunkStrategy", "words")
+	viperInstance.SetDefault("embeddings.TokenizerPath", "")
 	viperInstance.SetDefault("embeddings.ChunkOverlap", 100)
 	viperInstance.SetDefault("embeddings.ReturnChunks", false)
 	viperInstance.SetDefault("embeddings.ChunkBySelf", false)
+	viperInstance.SetDefault("embeddings.IncludeChunkText", true)
+	viperInstance.SetDefault("embeddings.BatchSize", 16)
+	viperInstance.SetDefault("embeddings.Concurrency", 4)
+	viperInstance.SetDefault("embeddings.Cache.Enabled", false)
+	viperInstance.SetDefault("embeddings.Cache.MaxEntries", 20000)
+	viperInstance.SetDefault("embeddings.OpenAI.Model", "")
+	viperInstance.SetDefault("embeddings.OpenAI.ApiKey", "")
+	viperInstance.SetDefault("embeddings.OpenAI.Dimensions", 0)
 
+	viperInstance.SetDefault("watcher.Backend", "minio")
 	viperInstance.SetDefault("watcher.Address", "cloud-storage:2894")
 	viperInstance.SetDefault("watcher.Username", "minio-root")
 	viperInstance.SetDefault("watcher.Password", "minio-root")
 	viperInstance.SetDefault("watcher.EnableSSL", false)
 	viperInstance.SetDefault("watcher.WatchedDirectories", []string{"common-folder"})
-	viperInstance.SetDefault("watcher.CacheExpire", 10)
-	viperInstance.SetDefault("watcher.CacheCleanInterval", 30)
+	viperInstance.SetDefault("watcher.Backfill", false)
+	viperInstance.SetDefault("watcher.Reembed.Rate", 120)
+	viperInstance.SetDefault("watcher.Reembed.MaxPending", 4)
+
+	viperInstance.SetDefault("storage.Path", "./indexer/doc-watcher.db")
+	viperInstance.SetDefault("storage.Timeout", 10)
+
+	viperInstance.SetDefault("pipeline.Workers", 4)
+	viperInstance.SetDefault("pipeline.QueueSize", 1000)
+	viperInstance.SetDefault("pipeline.DownloadLimit", 4)
+	viperInstance.SetDefault("pipeline.OcrLimit", 2)
+	viperInstance.SetDefault("pipeline.EmbeddingsLimit", 2)
+	viperInstance.SetDefault("pipeline.StoreLimit", 4)
+	for _, policy := range []string{
+		"pipeline.DownloadRetry",
+		"pipeline.OcrRetry",
+		"pipeline.EmbeddingsRetry",
+		"pipeline.StoreRetry",
+		"webhooks.Retry",
+	} {
+		viperInstance.SetDefault(policy+".MaxAttempts", 3)
+		viperInstance.SetDefault(policy+".InitialBackoff", 1000)
+		viperInstance.SetDefault(policy+".MaxBackoff", 30000)
+		viperInstance.SetDefault(policy+".Multiplier", 2.0)
+		viperInstance.SetDefault(policy+".Jitter", 0.2)
+		viperInstance.SetDefault(policy+".RetryableStatuses", []int{408, 429, 500, 502, 503, 504})
+	}
+
+	viperInstance.SetDefault("webhooks.Timeout", 30)
 
 	if err := viperInstance.ReadInConfig(); err != nil {
 		confErr := fmt.Errorf("failed while reading config file %s: %w", filePath, err)
@@ -85,50 +154,159 @@ func LoadEnv(enableDotenv bool) (*Config, error) {
 	ocrAddr := loadString("DOC_WATCHER_OCR_ADDRESS")
 	enableSSL := loadBool("DOC_WATCHER_OCR_ENABLE_SSL")
 	ocrTimeout := loadNumber("DOC_WATCHER_OCR_TIMEOUT")
+	ocrDefaultRecognizer := loadString("DOC_WATCHER_OCR_DEFAULT_RECOGNIZER")
+	ocrFallbackRecognizer := loadString("DOC_WATCHER_OCR_FALLBACK_RECOGNIZER")
+	ocrQualityThreshold := loadNumber("DOC_WATCHER_OCR_QUALITY_THRESHOLD")
+	ocrRoutes := make([]*ocr.Route, 0)
+	if routesData := loadString("DOC_WATCHER_OCR_ROUTES"); len(routesData) > 0 {
+		if err := json.Unmarshal([]byte(routesData), &ocrRoutes); err != nil {
+			return nil, fmt.Errorf("failed to parse ocr routes: %w", err)
+		}
+	}
+
 	ocrConfig := ocr.Config{
-		Address:   ocrAddr,
-		EnableSSL: enableSSL,
-		Timeout:   time.Duration(ocrTimeout),
+		Address:            ocrAddr,
+		EnableSSL:          enableSSL,
+		Timeout:            time.Duration(ocrTimeout),
+		DefaultRecognizer:  ocrDefaultRecognizer,
+		FallbackRecognizer: ocrFallbackRecognizer,
+		Routes:             ocrRoutes,
+		QualityThreshold:   int32(ocrQualityThreshold),
+		Async: ocr.AsyncConfig{
+			Enabled:         loadBool("DOC_WATCHER_OCR_ASYNC_ENABLED"),
+			PollInterval:    time.Duration(loadNumber("DOC_WATCHER_OCR_ASYNC_POLL_INTERVAL")),
+			MaxPollInterval: time.Duration(loadNumber("DOC_WATCHER_OCR_ASYNC_MAX_POLL_INTERVAL")),
+			Multiplier:      loadFloat("DOC_WATCHER_OCR_ASYNC_MULTIPLIER"),
+			JobTimeout:      time.Duration(loadNumber("DOC_WATCHER_OCR_ASYNC_JOB_TIMEOUT")),
+		},
+		Pages: ocr.PagesConfig{
+			Enabled:     loadBool("DOC_WATCHER_OCR_PAGES_ENABLED"),
+			Concurrency: loadNumber("DOC_WATCHER_OCR_PAGES_CONCURRENCY"),
+			Dpi:         loadNumber("DOC_WATCHER_OCR_PAGES_DPI"),
+			PdfCommand:  loadString("DOC_WATCHER_OCR_PAGES_PDF_COMMAND"),
+			TiffCommand: loadString("DOC_WATCHER_OCR_PAGES_TIFF_COMMAND"),
+		},
+		Tesseract: ocr.TesseractConfig{
+			Command:    loadString("DOC_WATCHER_OCR_TESSERACT_COMMAND"),
+			PdfCommand: loadString("DOC_WATCHER_OCR_TESSERACT_PDF_COMMAND"),
+			Languages:  strings.Split(loadString("DOC_WATCHER_OCR_TESSERACT_LANGUAGES"), ","),
+			Dpi:        loadNumber("DOC_WATCHER_OCR_TESSERACT_DPI"),
+			Timeout:    time.Duration(loadNumber("DOC_WATCHER_OCR_TESSERACT_TIMEOUT")),
+		},
+		Native: ocr.NativeConfig{
+			MaxDecodedSize: int64(loadNumber("DOC_WATCHER_OCR_NATIVE_MAX_DECODED_SIZE")),
+		},
 	}
 
 	searchAddr := loadString("DOC_WATCHER_SEARCHER_ADDRESS")
 	searchEnableSSL := loadBool("DOC_WATCHER_SEARCHER_ENABLE_SSL")
+	searchStorageMode := loadString("DOC_WATCHER_SEARCHER_STORAGE_MODE")
+	searchConcurrency := loadNumber("DOC_WATCHER_SEARCHER_CONCURRENCY")
 	searchConfig := searcher.Config{
-		Address:   searchAddr,
-		EnableSSL: searchEnableSSL,
+		Address:     searchAddr,
+		EnableSSL:   searchEnableSSL,
+		StorageMode: searchStorageMode,
+		Concurrency: searchConcurrency,
 	}
 
+	embBackend := loadString("DOC_WATCHER_EMBEDDINGS_BACKEND")
+	embModel := loadString("DOC_WATCHER_EMBEDDINGS_MODEL")
 	embAddress := loadString("DOC_WATCHER_EMBEDDINGS_ADDRESS")
 	embEnableSSL := loadBool("DOC_WATCHER_EMBEDDINGS_ENABLE_SSL")
 	embChunkSize := loadNumber("DOC_WATCHER_EMBEDDINGS_CHUNK_SIZE")
+	embChunkStrategy := loadString("DOC_WATCHER_EMBEDDINGS_CHUNK_STRATEGY")
+	embTokenizerPath := loadString("DOC_WATCHER_EMBEDDINGS_TOKENIZER_PATH")
 	embChunkOverlap := loadNumber("DOC_WATCHER_EMBEDDINGS_CHUNK_OVERLAP")
 	embRetChunks := loadBool("DOC_WATCHER_EMBEDDINGS_RETURN_CHUNKS")
 	embChunkBySelf := loadBool("DOC_WATCHER_EMBEDDINGS_SELF_CHUNK")
+	embChunkText := loadBool("DOC_WATCHER_EMBEDDINGS_INCLUDE_CHUNK_TEXT")
+	embBatchSize := loadNumber("DOC_WATCHER_EMBEDDINGS_BATCH_SIZE")
+	embConcurrency := loadNumber("DOC_WATCHER_EMBEDDINGS_CONCURRENCY")
 	embConfig := embeddings.Config{
-		Address:      embAddress,
-		EnableSSL:    embEnableSSL,
-		ChunkSize:    embChunkSize,
-		ChunkOverlap: embChunkOverlap,
-		ReturnChunks: embRetChunks,
-		ChunkBySelf:  embChunkBySelf,
+		Backend:          embBackend,
+		Model:            embModel,
+		Address:          embAddress,
+		EnableSSL:        embEnableSSL,
+		ChunkSize:        embChunkSize,
+		ChunkStrategy:    embChunkStrategy,
+		TokenizerPath:    embTokenizerPath,
+		ChunkOverlap:     embChunkOverlap,
+		ReturnChunks:     embRetChunks,
+		ChunkBySelf:      embChunkBySelf,
+		BatchSize:        embBatchSize,
+		Concurrency:      embConcurrency,
+		IncludeChunkText: embChunkText,
+		Cache: embeddings.CacheConfig{
+			Enabled:    loadBool("DOC_WATCHER_EMBEDDINGS_CACHE_ENABLED"),
+			MaxEntries: loadNumber("DOC_WATCHER_EMBEDDINGS_CACHE_MAX_ENTRIES"),
+		},
+		OpenAI: embeddings.OpenAIConfig{
+			Model:      loadString("DOC_WATCHER_EMBEDDINGS_OPENAI_MODEL"),
+			ApiKey:     loadString("DOC_WATCHER_EMBEDDINGS_OPENAI_API_KEY"),
+			Dimensions: loadNumber("DOC_WATCHER_EMBEDDINGS_OPENAI_DIMENSIONS"),
+		},
 	}
 
+	watchBackend := loadString("DOC_WATCHER_BACKEND")
 	watchAddress := loadString("DOC_WATCHER_ADDRESS")
 	watchEnableSSL := loadBool("DOC_WATCHER_ENABLE_SSL")
 	watchUsername := loadString("DOC_WATCHER_USERNAME")
 	watchPassword := loadString("DOC_WATCHER_PASSWORD")
-	watchCacheExpire := loadNumber("DOC_WATCHER_CACHE_EXPIRE")
-	watchCacheCleanInterval := loadNumber("DOC_WATCHER_CACHE_CLEAN_INTERVAL")
+	watchBackfill := loadBool("DOC_WATCHER_BACKFILL")
 
 	watchDirectories := strings.Split(loadString("DOC_WATCHER_WATCHED_DIRS"), ",")
 	watchConfig := watcher.Config{
+		Backend:            watchBackend,
 		Address:            watchAddress,
 		EnableSSL:          watchEnableSSL,
 		Username:           watchUsername,
 		Password:           watchPassword,
-		CacheExpire:        time.Duration(watchCacheExpire),
-		CacheCleanInterval: time.Duration(watchCacheCleanInterval),
 		WatchedDirectories: watchDirectories,
+		Backfill:           watchBackfill,
+		Reembed: watcher.ReembedConfig{
+			Rate:       loadNumber("DOC_WATCHER_REEMBED_RATE"),
+			MaxPending: loadNumber("DOC_WATCHER_REEMBED_MAX_PENDING"),
+		},
+	}
+
+	storagePath := loadString("DOC_WATCHER_STORAGE_PATH")
+	storageTimeout := loadNumber("DOC_WATCHER_STORAGE_TIMEOUT")
+	storageConfig := storage.Config{
+		Path:    storagePath,
+		Timeout: time.Duration(storageTimeout),
+	}
+
+	pipeWorkers := loadNumber("DOC_WATCHER_PIPELINE_WORKERS")
+	pipeQueueSize := loadNumber("DOC_WATCHER_PIPELINE_QUEUE_SIZE")
+	pipeDownloadLimit := loadNumber("DOC_WATCHER_PIPELINE_DOWNLOAD_LIMIT")
+	pipeOcrLimit := loadNumber("DOC_WATCHER_PIPELINE_OCR_LIMIT")
+	pipeEmbeddingsLimit := loadNumber("DOC_WATCHER_PIPELINE_EMBEDDINGS_LIMIT")
+	pipeStoreLimit := loadNumber("DOC_WATCHER_PIPELINE_STORE_LIMIT")
+	pipeConfig := pipeline.Config{
+		Workers:         pipeWorkers,
+		QueueSize:       pipeQueueSize,
+		DownloadLimit:   pipeDownloadLimit,
+		OcrLimit:        pipeOcrLimit,
+		EmbeddingsLimit: pipeEmbeddingsLimit,
+		StoreLimit:      pipeStoreLimit,
+		DownloadRetry:   loadRetryPolicy("DOC_WATCHER_PIPELINE_DOWNLOAD_RETRY"),
+		OcrRetry:        loadRetryPolicy("DOC_WATCHER_PIPELINE_OCR_RETRY"),
+		EmbeddingsRetry: loadRetryPolicy("DOC_WATCHER_PIPELINE_EMBEDDINGS_RETRY"),
+		StoreRetry:      loadRetryPolicy("DOC_WATCHER_PIPELINE_STORE_RETRY"),
+	}
+
+	webhooksTimeout := loadNumber("DOC_WATCHER_WEBHOOKS_TIMEOUT")
+	webhooksSubscriptions := make([]*webhooks.Subscription, 0)
+	if subsData := loadString("DOC_WATCHER_WEBHOOKS_SUBSCRIPTIONS"); len(subsData) > 0 {
+		if err := json.Unmarshal([]byte(subsData), &webhooksSubscriptions); err != nil {
+			return nil, fmt.Errorf("failed to parse webhooks subscriptions: %w", err)
+		}
+	}
+
+	webhooksConfig := webhooks.Config{
+		Timeout:       time.Duration(webhooksTimeout),
+		Retry:         loadRetryPolicy("DOC_WATCHER_WEBHOOKS_RETRY"),
+		Subscriptions: webhooksSubscriptions,
 	}
 
 	return &Config{
@@ -137,9 +315,29 @@ func LoadEnv(enableDotenv bool) (*Config, error) {
 		Server:     serverConfig,
 		Embeddings: embConfig,
 		Watcher:    watchConfig,
+		Storage:    storageConfig,
+		Pipeline:   pipeConfig,
+		Webhooks:   webhooksConfig,
 	}, nil
 }
 
+func loadRetryPolicy(envPrefix string) retry.Policy {
+	maxAttempts := loadNumber(envPrefix + "_MAX_ATTEMPTS")
+	initialBackoff := loadNumber(envPrefix + "_INITIAL_BACKOFF")
+	maxBackoff := loadNumber(envPrefix + "_MAX_BACKOFF")
+	multiplier := loadFloat(envPrefix + "_MULTIPLIER")
+	jitter := loadFloat(envPrefix + "_JITTER")
+	retryableStatuses := loadNumbers(envPrefix + "_RETRYABLE_STATUSES")
+	return retry.Policy{
+		MaxAttempts:       maxAttempts,
+		InitialBackoff:    time.Duration(initialBackoff),
+		MaxBackoff:        time.Duration(maxBackoff),
+		Multiplier:        multiplier,
+		Jitter:            jitter,
+		RetryableStatuses: retryableStatuses,
+	}
+}
+
 func loadString(envName string) string {
 	value, exists := os.LookupEnv(envName)
 	if !exists {
@@ -185,3 +383,41 @@ func loadBool(envName string) bool {
 
 	return boolean
 }
+
+func loadFloat(envName string) float64 {
+	value, exists := os.LookupEnv(envName)
+	if !exists {
+		msg := fmt.Sprintf("failed to extract %s env var: %s", envName, value)
+		log.Println(msg)
+		return 0
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		msg := fmt.Sprintf("failed to convert %s env var: %s", envName, value)
+		log.Println(msg)
+		return 0
+	}
+
+	return number
+}
+
+func loadNumbers(envName string) []int {
+	numbers := make([]int, 0)
+	for _, value := range strings.Split(loadString(envName), ",") {
+		if len(value) == 0 {
+			continue
+		}
+
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			msg := fmt.Sprintf("failed to convert %s env var: %s", envName, value)
+			log.Println(msg)
+			continue
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	return numbers
+}
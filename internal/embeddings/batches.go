@@ -0,0 +1,69 @@
+package embeddings
+
+import (
+	"context"
+	"sync"
+)
+
+// LoadBatches loads vectors of texts by batches concurrently bounded by
+// concurrency and returns vectors in order of texts. Batches being loaded
+// are canceled after the first failed one.
+func LoadBatches(
+	ctx context.Context,
+	texts []string,
+	batchSize, concurrency int,
+	load func(ctx context.Context, batch []string) ([][]float64, error),
+) ([][]float64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	vectors := make([][]float64, len(texts))
+	batchSize = max(batchSize, 1)
+	limit := make(chan struct{}, max(concurrency, 1))
+
+	var errOnce sync.Once
+	var batchErr error
+
+	wg := &sync.WaitGroup{}
+	for start := 0; start < len(texts); start += batchSize {
+		batch := texts[start:min(start+batchSize, len(texts))]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+			case <-ctx.Done():
+				errOnce.Do(func() { batchErr = ctx.Err() })
+				return
+			}
+
+			batchVectors, err := load(ctx, batch)
+			if err != nil {
+				errOnce.Do(func() {
+					batchErr = err
+					cancel()
+				})
+				return
+			}
+
+			copy(vectors[start:], batchVectors)
+		}()
+	}
+
+	wg.Wait()
+	if batchErr != nil {
+		return nil, batchErr
+	}
+
+	return vectors, nil
+}
+
+// DropChunkedText clears chunks text keeping only their vectors and offsets.
+func (c *ComputeTokens) DropChunkedText() {
+	for index := range c.ChunkedText {
+		c.ChunkedText[index] = ""
+	}
+}
@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/storage"
+)
+
+const (
+	cacheBucket = "embeddings-cache"
+	// accessBucket keeps cache keys ordered by their last access time.
+	accessBucket = "embeddings-cache-access"
+	// accessedBucket maps cache keys to their keys of access bucket.
+	accessedBucket = "embeddings-cache-accessed"
+	// evictRatio is a part of entries evicted at once when cache is full.
+	evictRatio = 0.1
+)
+
+// errEvictionFilled stops iteration when enough entries are collected.
+var errEvictionFilled = errors.New("eviction is filled")
+
+// Service caches chunks vectors in storage by hash of model and normalized
+// chunk text, so unchanged and repeated chunks are not sent to embeddings
+// service again. The least recently used entries are evicted when cache
+// is full.
+type Service struct {
+	config *embeddings.CacheConfig
+	store  *storage.Service
+
+	// mu is held across changes of buckets and entries count, so
+	// count does not drift from stored entries.
+	mu      sync.Mutex
+	entries int
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+type entry struct {
+	Vector []float64 `json:"vector"`
+}
+
+func New(config *embeddings.CacheConfig, store *storage.Service) *Service {
+	service := &Service{
+		config: config,
+		store:  store,
+	}
+
+	if !config.Enabled {
+		return service
+	}
+
+	err := store.ForEach(accessedBucket, func(_ string, _ []byte) error {
+		service.entries++
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("failed to count embeddings cache entries: %v", err)
+	}
+
+	return service
+}
+
+// Load returns vectors of texts in their order taking cached ones from
+// storage and loading others by passed function.
+func (s *Service) Load(model string, texts []string, load func(texts []string) ([][]float64, error)) ([][]float64, error) {
+	if !s.config.Enabled {
+		return load(texts)
+	}
+
+	vectors := make([][]float64, len(texts))
+	cached := make(map[string][]float64)
+	missed := make(map[string][]int)
+	missedTexts := make([]string, 0)
+	missedKeys := make([]string, 0)
+	err := s.store.View(func(tx *storage.Tx) error {
+		for index, text := range texts {
+			key := cacheKey(model, text)
+			if positions, ok := missed[key]; ok {
+				missed[key] = append(positions, index)
+				continue
+			}
+
+			if vector, ok := cached[key]; ok {
+				s.hits.Add(1)
+				vectors[index] = vector
+				continue
+			}
+
+			value := &entry{}
+			ok, err := tx.Get(cacheBucket, key, value)
+			if err != nil {
+				log.Printf("failed to get cached embeddings: %v", err)
+			}
+
+			if ok {
+				s.hits.Add(1)
+				cached[key] = value.Vector
+				vectors[index] = value.Vector
+				continue
+			}
+
+			s.misses.Add(1)
+			missed[key] = []int{index}
+			missedTexts = append(missedTexts, text)
+			missedKeys = append(missedKeys, key)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("failed to read embeddings cache: %v", err)
+		return load(texts)
+	}
+
+	loaded := make(map[string][]float64, len(missedKeys))
+	if len(missedTexts) > 0 {
+		vectorsLoaded, err := load(missedTexts)
+		if err != nil {
+			return nil, err
+		}
+
+		for index, key := range missedKeys {
+			for _, position := range missed[key] {
+				vectors[position] = vectorsLoaded[index]
+			}
+			loaded[key] = vectorsLoaded[index]
+		}
+	}
+
+	s.save(cached, loaded)
+	return vectors, nil
+}
+
+// save stores loaded vectors and moves all used entries to the end of
+// access order by single transaction.
+func (s *Service) save(cached, loaded map[string][]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := 0
+	accessedAt := time.Now().UnixNano()
+	err := s.store.Update(func(tx *storage.Tx) error {
+		for key, vector := range loaded {
+			if err := tx.Put(cacheBucket, key, &entry{Vector: vector}); err != nil {
+				return err
+			}
+		}
+
+		keys := make([]string, 0, len(cached)+len(loaded))
+		for key := range cached {
+			keys = append(keys, key)
+		}
+		for key := range loaded {
+			keys = append(keys, key)
+		}
+
+		for index, key := range keys {
+			var previous string
+			found, err := tx.Get(accessedBucket, key, &previous)
+			if err != nil {
+				return err
+			}
+
+			if found {
+				if err = tx.Delete(accessBucket, previous); err != nil {
+					return err
+				}
+			} else {
+				added++
+			}
+
+			accessKey := fmt.Sprintf("%020d-%s", accessedAt+int64(index), key)
+			if err = tx.Put(accessBucket, accessKey, key); err != nil {
+				return err
+			}
+
+			if err = tx.Put(accessedBucket, key, accessKey); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("failed to store cached embeddings: %v", err)
+		return
+	}
+
+	s.entries += added
+	if s.config.MaxEntries > 0 && s.entries > s.config.MaxEntries {
+		s.evict()
+	}
+}
+
+// evict deletes the least recently used entries to free part of cache at
+// once. Entries are taken from access bucket, so vectors are not read.
+// Lock of entries count must be held by caller.
+func (s *Service) evict() {
+	limit := s.config.MaxEntries - int(float64(s.config.MaxEntries)*evictRatio)
+	count := s.entries - limit
+
+	accessKeys := make([]string, 0, count)
+	keys := make([]string, 0, count)
+	err := s.store.ForEach(accessBucket, func(accessKey string, _ []byte) error {
+		if len(keys) >= count {
+			return errEvictionFilled
+		}
+
+		_, key, _ := strings.Cut(accessKey, "-")
+		accessKeys = append(accessKeys, accessKey)
+		keys = append(keys, key)
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errEvictionFilled) {
+		log.Printf("failed to load embeddings cache entries: %v", err)
+		return
+	}
+
+	err = s.store.Update(func(tx *storage.Tx) error {
+		if err := tx.Delete(cacheBucket, keys...); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(accessedBucket, keys...); err != nil {
+			return err
+		}
+
+		return tx.Delete(accessBucket, accessKeys...)
+	})
+
+	if err != nil {
+		log.Printf("failed to evict embeddings cache entries: %v", err)
+		return
+	}
+
+	s.entries -= len(keys)
+}
+
+// Stats returns cache entries count and hits since start.
+func (s *Service) Stats() *embeddings.CacheStats {
+	s.mu.Lock()
+	entries := s.entries
+	s.mu.Unlock()
+
+	hits, misses := s.hits.Load(), s.misses.Load()
+	stats := &embeddings.CacheStats{
+		Enabled:    s.config.Enabled,
+		Entries:    entries,
+		MaxEntries: s.config.MaxEntries,
+		Hits:       hits,
+		Misses:     misses,
+	}
+
+	if hits+misses > 0 {
+		stats.HitRate = float64(hits) / float64(hits+misses)
+	}
+
+	return stats
+}
+
+// Purge deletes all cached vectors and resets stats.
+func (s *Service) Purge() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, bucket := range []string{cacheBucket, accessBucket, accessedBucket} {
+		if err := s.store.DeleteBucket(bucket); err != nil {
+			return err
+		}
+	}
+
+	s.entries = 0
+	s.hits.Store(0)
+	s.misses.Store(0)
+	return nil
+}
+
+// cacheKey hashes model with chunk text with collapsed whitespaces.
+func cacheKey(model, text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	hash := sha256.Sum256([]byte(model + "\x00" + normalized))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,410 @@
+package chunker
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/embeddings/tokenizer"
+)
+
+const (
+	// StrategyRunes splits content by chunk size in characters.
+	StrategyRunes = "runes"
+	// StrategyWords splits content by chunk size at word boundaries.
+	StrategyWords = "words"
+	// StrategySentences packs whole sentences preferring paragraph ends.
+	StrategySentences = "sentences"
+	// StrategyTokens packs whole words by chunk size in tokens of local tokenizer.
+	StrategyTokens = "tokens"
+)
+
+// Chunk is a text chunk and its byte offset into source content.
+type Chunk struct {
+	Text   string
+	Offset int
+}
+
+type Chunker interface {
+	Split(content string) []*Chunk
+}
+
+func New(config *embeddings.Config) Chunker {
+	size := max(config.ChunkSize, 1)
+	overlap := min(max(config.ChunkOverlap, 0), size-1)
+	switch config.ChunkStrategy {
+	case StrategyRunes:
+		return &RuneChunker{size: size, overlap: overlap}
+	case StrategyWords, "":
+		return &WordChunker{size: size, overlap: overlap}
+	case StrategySentences:
+		return &SentenceChunker{size: size, overlap: overlap}
+	case StrategyTokens:
+		tokenCounter, err := tokenizer.Load(config.TokenizerPath)
+		if err != nil {
+			log.Fatalln("failed to load tokenizer: ", err)
+		}
+		return &TokenChunker{size: size, overlap: overlap, tokenizer: tokenCounter}
+	default:
+		log.Fatalln("unknown chunk strategy: ", config.ChunkStrategy)
+		return nil
+	}
+}
+
+// RuneChunker splits content by characters never cutting multibyte runes.
+type RuneChunker struct {
+	size    int
+	overlap int
+}
+
+// Split cuts windows of chunk size moved by chunk size without overlap,
+// so each chunk but the last one is full.
+func (c *RuneChunker) Split(content string) []*Chunk {
+	offsets := make([]int, 0, len(content)+1)
+	for index := range content {
+		offsets = append(offsets, index)
+	}
+	runesCount := len(offsets)
+	offsets = append(offsets, len(content))
+
+	spans := make([]span, 0)
+	for start := 0; start < runesCount; start += c.size - c.overlap {
+		end := min(start+c.size, runesCount)
+		spans = append(spans, span{start: offsets[start], end: offsets[end]})
+		if end == runesCount {
+			break
+		}
+	}
+
+	return collectChunks(content, spans)
+}
+
+// WordChunker packs whole words into chunks, too long words are split by runes.
+type WordChunker struct {
+	size    int
+	overlap int
+}
+
+func (c *WordChunker) Split(content string) []*Chunk {
+	size := c.size - c.overlap
+	segments := splitWords(content, span{end: len(content)})
+	spans := pack(content, segments, size, utf8.RuneCountInString, func(segment span) []span {
+		return splitRunes(content, segment, size)
+	})
+
+	spans = overlapSpans(content, spans, c.overlap, spanStarts(segments))
+	return collectChunks(content, spans)
+}
+
+// SentenceChunker packs whole sentences into chunks and breaks chunks at
+// paragraph ends, too long sentences are split by words.
+type SentenceChunker struct {
+	size    int
+	overlap int
+}
+
+func (c *SentenceChunker) Split(content string) []*Chunk {
+	size := c.size - c.overlap
+	segments := splitSentences(content)
+	spans := pack(content, segments, size, utf8.RuneCountInString, func(segment span) []span {
+		words := splitWords(content, segment)
+		return pack(content, words, size, utf8.RuneCountInString, func(word span) []span {
+			return splitRunes(content, word, size)
+		})
+	})
+
+	words := splitWords(content, span{end: len(content)})
+	spans = overlapSpans(content, spans, c.overlap, spanStarts(words))
+	return collectChunks(content, spans)
+}
+
+// TokenChunker packs whole words into chunks by tokens count, too long
+// words are halved until their parts fit into chunk.
+type TokenChunker struct {
+	size      int
+	overlap   int
+	tokenizer *tokenizer.Tokenizer
+}
+
+func (c *TokenChunker) Split(content string) []*Chunk {
+	size := c.size - c.overlap
+	words := splitWords(content, span{end: len(content)})
+
+	var halve func(segment span) []span
+	halve = func(segment span) []span {
+		length := utf8.RuneCountInString(strings.TrimRightFunc(content[segment.start:segment.end], unicode.IsSpace))
+		if length < 2 {
+			return []span{segment}
+		}
+		parts := splitRunes(content, segment, (length+1)/2)
+		return pack(content, parts, size, c.tokenizer.Count, halve)
+	}
+
+	spans := pack(content, words, size, c.tokenizer.Count, halve)
+	spans = c.overlapSpans(content, spans, spanStarts(words))
+	return collectChunks(content, spans)
+}
+
+// overlapSpans moves start of each span back to the earliest word start
+// keeping overlap within overlap tokens.
+func (c *TokenChunker) overlapSpans(content string, spans []span, boundaries []int) []span {
+	if c.overlap <= 0 {
+		return spans
+	}
+
+	overlapped := make([]span, len(spans))
+	copy(overlapped, spans)
+	for index := 1; index < len(spans); index++ {
+		current, previous := spans[index], spans[index-1]
+
+		position := sort.SearchInts(boundaries, current.start) - 1
+		for ; position >= 0 && boundaries[position] > previous.start; position-- {
+			if c.tokenizer.Count(content[boundaries[position]:current.start]) > c.overlap {
+				break
+			}
+			overlapped[index].start = boundaries[position]
+		}
+	}
+
+	return dropContained(overlapped)
+}
+
+// span is a byte range of content. Chunk is closed after span with
+// paragraphEnd flag if it is filled at least by half.
+type span struct {
+	start        int
+	end          int
+	paragraphEnd bool
+}
+
+// pack joins consecutive segments into spans up to size measured by measure.
+func pack(content string, segments []span, size int, measure func(text string) int, fallback func(segment span) []span) []span {
+	spans := make([]span, 0)
+
+	current, currentLen := span{start: -1}, 0
+	flush := func() {
+		if current.start >= 0 {
+			spans = append(spans, current)
+		}
+		current, currentLen = span{start: -1}, 0
+	}
+
+	for _, segment := range segments {
+		segmentLen := measure(strings.TrimRightFunc(content[segment.start:segment.end], unicode.IsSpace))
+		if segmentLen > size {
+			flush()
+			spans = append(spans, fallback(segment)...)
+			continue
+		}
+
+		if currentLen > 0 && currentLen+segmentLen > size {
+			flush()
+		}
+
+		if current.start < 0 {
+			current.start = segment.start
+		}
+		current.end = segment.end
+		currentLen += measure(content[segment.start:segment.end])
+
+		if segment.paragraphEnd && currentLen*2 >= size {
+			flush()
+		}
+	}
+
+	flush()
+	return spans
+}
+
+// overlapSpans moves start of each span back by overlap in runes, so
+// chunks are sliding windows. Start is aligned to the nearest boundary
+// within overlap.
+func overlapSpans(content string, spans []span, overlap int, boundaries []int) []span {
+	if overlap <= 0 {
+		return spans
+	}
+
+	overlapped := make([]span, len(spans))
+	copy(overlapped, spans)
+	for index := 1; index < len(spans); index++ {
+		current, previous := spans[index], spans[index-1]
+
+		target := current.start
+		for count := 0; count < overlap && target > previous.start; count++ {
+			_, size := utf8.DecodeLastRuneInString(content[:target])
+			target -= size
+		}
+
+		position := sort.SearchInts(boundaries, target)
+		if position < len(boundaries) && boundaries[position] < current.start {
+			overlapped[index].start = boundaries[position]
+		}
+	}
+
+	return dropContained(overlapped)
+}
+
+// dropContained drops spans which are fully contained into next overlapped
+// span, so the same text is not embedded twice.
+func dropContained(spans []span) []span {
+	kept := make([]span, 0, len(spans))
+	for index, current := range spans {
+		if index+1 < len(spans) && spans[index+1].start <= current.start {
+			continue
+		}
+		kept = append(kept, current)
+	}
+
+	return kept
+}
+
+func spanStarts(spans []span) []int {
+	starts := make([]int, 0, len(spans))
+	for _, segment := range spans {
+		starts = append(starts, segment.start)
+	}
+
+	return starts
+}
+
+func splitRunes(content string, segment span, size int) []span {
+	spans := make([]span, 0)
+
+	start, count := segment.start, 0
+	for index := range content[segment.start:segment.end] {
+		if count == size {
+			spans = append(spans, span{start: start, end: segment.start + index})
+			start, count = segment.start+index, 0
+		}
+		count++
+	}
+
+	if start < segment.end {
+		spans = append(spans, span{start: start, end: segment.end})
+	}
+
+	return spans
+}
+
+// splitWords splits segment into words with their trailing spaces.
+func splitWords(content string, segment span) []span {
+	spans := make([]span, 0)
+
+	start, inSpace := segment.start, false
+	for index, r := range content[segment.start:segment.end] {
+		position := segment.start + index
+		isSpace := unicode.IsSpace(r)
+		if !isSpace && inSpace && position > start {
+			spans = append(spans, span{start: start, end: position})
+			start = position
+		}
+		inSpace = isSpace
+	}
+
+	if start < segment.end {
+		spans = append(spans, span{start: start, end: segment.end})
+	}
+
+	return spans
+}
+
+// splitSentences splits content into sentences with their trailing spaces.
+// Sentence ends by terminal punctuation followed by space or by a newline,
+// fullwidth terminal punctuation of CJK texts ends sentence by itself.
+func splitSentences(content string) []span {
+	spans := make([]span, 0)
+
+	start, terminated, fullwidth := 0, false, false
+	for index, r := range content {
+		switch {
+		case isTerminal(r):
+			terminated, fullwidth = true, isFullwidthTerminal(r)
+			continue
+		case terminated && isClosing(r):
+			continue
+		}
+
+		if fullwidth && !unicode.IsSpace(r) && index > start {
+			spans = append(spans, span{start: start, end: index})
+			start = index
+		}
+
+		if unicode.IsSpace(r) && (terminated || r == '\n') {
+			end := skipSpaces(content, index)
+			if end > start {
+				paragraphEnd := strings.Count(content[index:end], "\n") > 1
+				spans = append(spans, span{start: start, end: end, paragraphEnd: paragraphEnd})
+				start = end
+			}
+		}
+		terminated, fullwidth = false, false
+	}
+
+	if start < len(content) {
+		spans = append(spans, span{start: start, end: len(content), paragraphEnd: true})
+	}
+
+	return mergeSpans(spans)
+}
+
+// mergeSpans joins spans overlapped after skipping spaces of previous ones.
+func mergeSpans(spans []span) []span {
+	merged := make([]span, 0, len(spans))
+	for _, current := range spans {
+		if len(merged) > 0 && current.start < merged[len(merged)-1].end {
+			last := &merged[len(merged)-1]
+			last.end = max(last.end, current.end)
+			last.paragraphEnd = last.paragraphEnd || current.paragraphEnd
+			continue
+		}
+		merged = append(merged, current)
+	}
+
+	return merged
+}
+
+func skipSpaces(content string, index int) int {
+	for index < len(content) {
+		r, size := utf8.DecodeRuneInString(content[index:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		index += size
+	}
+
+	return index
+}
+
+func isTerminal(r rune) bool {
+	return strings.ContainsRune(".!?…。！？", r)
+}
+
+func isFullwidthTerminal(r rune) bool {
+	return strings.ContainsRune("。！？", r)
+}
+
+func isClosing(r rune) bool {
+	return strings.ContainsRune("\"'»”)]", r)
+}
+
+// collectChunks converts spans to chunks without surrounding spaces.
+func collectChunks(content string, spans []span) []*Chunk {
+	chunks := make([]*Chunk, 0, len(spans))
+	for _, segment := range spans {
+		text := content[segment.start:segment.end]
+		trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+		offset := segment.start + len(text) - len(trimmed)
+
+		trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		chunks = append(chunks, &Chunk{Text: trimmed, Offset: offset})
+	}
+
+	return chunks
+}
@@ -0,0 +1,221 @@
+package chunker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"doc-watcher/internal/embeddings"
+)
+
+// wordPieceTokenizer has whole words and single letter pieces, so known
+// words are single tokens and other words are counted by letters.
+const wordPieceTokenizer = `{
+	"normalizer": {"type": "BertNormalizer", "lowercase": true},
+	"pre_tokenizer": {"type": "BertPreTokenizer"},
+	"model": {
+		"type": "WordPiece",
+		"unk_token": "[UNK]",
+		"vocab": {
+			"[UNK]": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+			"six": 6, ".": 7, "a": 8, "##a": 9
+		}
+	}
+}`
+
+func newChunker(t *testing.T, strategy string, size, overlap int) Chunker {
+	t.Helper()
+
+	config := &embeddings.Config{ChunkStrategy: strategy, ChunkSize: size, ChunkOverlap: overlap}
+	if strategy == StrategyTokens {
+		config.TokenizerPath = filepath.Join(t.TempDir(), "tokenizer.json")
+		if err := os.WriteFile(config.TokenizerPath, []byte(wordPieceTokenizer), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return New(config)
+}
+
+func chunkTexts(chunks []*Chunk) []string {
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, chunk.Text)
+	}
+
+	return texts
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		size     int
+		overlap  int
+		content  string
+		chunks   []string
+	}{
+		{
+			name:     "runes keep multibyte characters",
+			strategy: StrategyRunes,
+			size:     4,
+			content:  "ПриветМир",
+			chunks:   []string{"Прив", "етМи", "р"},
+		},
+		{
+			name:     "runes of cjk text",
+			strategy: StrategyRunes,
+			size:     3,
+			content:  "東京都の天気は晴れ",
+			chunks:   []string{"東京都", "の天気", "は晴れ"},
+		},
+		{
+			name:     "runes with overlap",
+			strategy: StrategyRunes,
+			size:     4,
+			overlap:  2,
+			content:  "абвгдеж",
+			chunks:   []string{"абвг", "вгде", "деж"},
+		},
+		{
+			name:     "runes with overlap of half size",
+			strategy: StrategyRunes,
+			size:     4,
+			overlap:  3,
+			content:  "абвгде",
+			chunks:   []string{"абвг", "бвгд", "вгде"},
+		},
+		{
+			name:     "words of cyrillic text",
+			strategy: StrategyWords,
+			size:     12,
+			content:  "Съешь же ещё этих мягких французских булок",
+			chunks:   []string{"Съешь же ещё", "этих мягких", "французских", "булок"},
+		},
+		{
+			name:     "words split too long word",
+			strategy: StrategyWords,
+			size:     4,
+			content:  "ab привет cd",
+			chunks:   []string{"ab", "прив", "ет", "cd"},
+		},
+		{
+			name:     "words of cjk text without spaces",
+			strategy: StrategyWords,
+			size:     4,
+			content:  "東京都の天気は晴れ",
+			chunks:   []string{"東京都の", "天気は晴", "れ"},
+		},
+		{
+			name:     "words with overlap",
+			strategy: StrategyWords,
+			size:     12,
+			overlap:  4,
+			content:  "один два три четыре пять",
+			chunks:   []string{"один два", "два три", "три четыре", "пять"},
+		},
+		{
+			name:     "words with overlap do not repeat contained chunk",
+			strategy: StrategyWords,
+			size:     8,
+			overlap:  6,
+			content:  "аа бб вв гг",
+			chunks:   []string{"аа бб", "бб вв", "вв гг"},
+		},
+		{
+			name:     "sentences of cyrillic text",
+			strategy: StrategySentences,
+			size:     30,
+			content:  "Первое предложение. Второе! Третье предложение тут?",
+			chunks:   []string{"Первое предложение. Второе!", "Третье предложение тут?"},
+		},
+		{
+			name:     "sentences of cjk text",
+			strategy: StrategySentences,
+			size:     8,
+			content:  "今日は晴れです。明日は雨です。明後日は雪。",
+			chunks:   []string{"今日は晴れです。", "明日は雨です。", "明後日は雪。"},
+		},
+		{
+			name:     "sentences break at paragraph end",
+			strategy: StrategySentences,
+			size:     20,
+			content:  "Один абзац тут.\n\nДругой абзац.",
+			chunks:   []string{"Один абзац тут.", "Другой абзац."},
+		},
+		{
+			name:     "sentences with overlap",
+			strategy: StrategySentences,
+			size:     20,
+			overlap:  6,
+			content:  "Раз два. Три четыре. Пять шесть.",
+			chunks:   []string{"Раз два.", "два. Три четыре.", "Пять шесть."},
+		},
+		{
+			name:     "tokens of known words",
+			strategy: StrategyTokens,
+			size:     3,
+			content:  "One two three four five six.",
+			chunks:   []string{"One two three", "four five", "six."},
+		},
+		{
+			name:     "tokens halve long word",
+			strategy: StrategyTokens,
+			size:     2,
+			content:  "one aaaa two",
+			chunks:   []string{"one", "aa", "aa", "two"},
+		},
+		{
+			name:     "tokens with overlap",
+			strategy: StrategyTokens,
+			size:     4,
+			overlap:  1,
+			content:  "one two three four five six",
+			chunks:   []string{"one two three", "three four five six"},
+		},
+		{
+			name:     "empty content",
+			strategy: StrategyWords,
+			size:     10,
+			content:  " \n\t ",
+			chunks:   []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := newChunker(t, test.strategy, test.size, test.overlap).Split(test.content)
+			if texts := chunkTexts(chunks); !reflect.DeepEqual(texts, test.chunks) {
+				t.Errorf("chunks = %q, want %q", texts, test.chunks)
+			}
+		})
+	}
+}
+
+func TestSplitOffsets(t *testing.T) {
+	content := "Съешь же ещё этих мягких французских булок, да выпей чаю.\n\n" +
+		"東京都の天気は晴れです。明日は雨です。\n" +
+		"The quick brown fox jumps over the lazy dog."
+
+	for _, strategy := range []string{StrategyRunes, StrategyWords, StrategySentences, StrategyTokens} {
+		for _, overlap := range []int{0, 4} {
+			chunker := newChunker(t, strategy, 16, overlap)
+			for _, chunk := range chunker.Split(content) {
+				if !utf8.ValidString(chunk.Text) {
+					t.Errorf("%s: chunk %q is not valid utf-8", strategy, chunk.Text)
+				}
+
+				end := chunk.Offset + len(chunk.Text)
+				if chunk.Offset < 0 || end > len(content) || content[chunk.Offset:end] != chunk.Text {
+					t.Errorf("%s: chunk %q does not match content at offset %d", strategy, chunk.Text, chunk.Offset)
+				}
+
+				if strategy != StrategyTokens && utf8.RuneCountInString(chunk.Text) > 16 {
+					t.Errorf("%s: chunk %q exceeds chunk size", strategy, chunk.Text)
+				}
+			}
+		}
+	}
+}
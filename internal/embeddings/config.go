@@ -1,10 +1,42 @@
 package embeddings
 
 type Config struct {
-	Address      string
-	EnableSSL    bool
-	ChunkSize    int
-	ChunkOverlap int
+	// Backend is an embeddings service API: sova or openai.
+	Backend string
+	// Model is a name of sova embeddings model, it is requested from
+	// service info when empty.
+	Model     string
+	Address   string
+	EnableSSL bool
+	ChunkSize int
+	// ChunkStrategy is a chunker strategy: runes, words, sentences or tokens.
+	ChunkStrategy string
+	// TokenizerPath is a path to tokenizer.json of tokens chunk strategy.
+	TokenizerPath string
+	ChunkOverlap  int
+	// ReturnChunks asks embeddings service chunking by itself to return
+	// chunks text, otherwise text is cut by returned boundaries.
 	ReturnChunks bool
 	ChunkBySelf  bool
+	// IncludeChunkText keeps chunks text in embeddings of indexed documents.
+	IncludeChunkText bool
+	// BatchSize is a number of chunks sent by single request.
+	BatchSize int
+	// Concurrency limits parallel batches requests of single document.
+	Concurrency int
+	OpenAI      OpenAIConfig
+	Cache       CacheConfig
+}
+
+type CacheConfig struct {
+	Enabled bool
+	// MaxEntries limits cached vectors count, 0 disables limit.
+	MaxEntries int
+}
+
+type OpenAIConfig struct {
+	Model  string
+	ApiKey string
+	// Dimensions truncates vectors by models supporting it, 0 keeps model default.
+	Dimensions int
 }
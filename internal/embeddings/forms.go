@@ -1,13 +1,63 @@
 package embeddings
 
+type CacheStats struct {
+	Enabled    bool    `json:"enabled"`
+	Entries    int     `json:"entries"`
+	MaxEntries int     `json:"max_entries"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hit_rate"`
+}
+
 type ComputeTokens struct {
 	Chunks      int
 	ChunkedText []string
-	Vectors     [][]float64
+	// Offsets are byte offsets of chunks into document content.
+	Offsets []int
+	Vectors [][]float64
+}
+
+// InfoForm describes model served by embeddings service.
+type InfoForm struct {
+	ModelID string `json:"model_id"`
+}
+
+// EmbedChunksForm asks embeddings service to split text into chunks itself.
+type EmbedChunksForm struct {
+	Inputs       string `json:"inputs"`
+	ChunkSize    int    `json:"chunk_size"`
+	ChunkOverlap int    `json:"chunk_overlap"`
+	ReturnChunks bool   `json:"return_chunks"`
+	Normalize    bool   `json:"normalize"`
 }
 
+// ChunkForm is a chunk computed by embeddings service with its boundaries
+// as characters offsets into passed text.
+type ChunkForm struct {
+	Text   string    `json:"text"`
+	Start  int       `json:"start"`
+	End    int       `json:"end"`
+	Vector []float64 `json:"vector"`
+}
+
+// EmbedAllForm is a batch of chunks to compute vectors in the same order.
 type EmbedAllForm struct {
-	Inputs    string `json:"inputs"`
-	Truncate  bool   `json:"truncate"`
-	Normalize bool   `json:"normalize"`
+	Inputs    []string `json:"inputs"`
+	Truncate  bool     `json:"truncate"`
+	Normalize bool     `json:"normalize"`
+}
+
+// OpenAIEmbedForm is a request of OpenAI compatible embeddings API.
+type OpenAIEmbedForm struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+type OpenAIEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
 }
@@ -0,0 +1,157 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/embeddings/cache"
+	"doc-watcher/internal/embeddings/chunker"
+	"doc-watcher/internal/sender"
+	"doc-watcher/internal/watcher"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	BackendName   = "openai"
+	EmbeddingsURL = "/v1/embeddings"
+)
+
+// requestTimeout is a timeout of single request to embeddings service.
+const requestTimeout = 300 * time.Second
+
+// Service computes embeddings by OpenAI compatible API served by vLLM,
+// LocalAI, llama.cpp server and others.
+type Service struct {
+	config  *embeddings.Config
+	chunker chunker.Chunker
+	client  *http.Client
+	cache   *cache.Service
+}
+
+func New(config *embeddings.Config, cacheServ *cache.Service) *embeddings.Service {
+	if len(config.OpenAI.Model) == 0 {
+		log.Fatalln("openai embeddings backend requires model name")
+	}
+
+	if config.ChunkBySelf {
+		log.Println("openai embeddings backend does not chunk content by self")
+	}
+
+	servClient := &Service{
+		config:  config,
+		chunker: chunker.New(config),
+		client:  &http.Client{Timeout: requestTimeout},
+		cache:   cacheServ,
+	}
+
+	return &embeddings.Service{
+		Tokenizer: servClient,
+	}
+}
+
+func (s *Service) Tokenize(ctx context.Context, doc *watcher.Document) (*embeddings.ComputeTokens, error) {
+	computedTokens := &embeddings.ComputeTokens{
+		Chunks:      0,
+		ChunkedText: []string{},
+		Offsets:     []int{},
+		Vectors:     [][]float64{},
+	}
+
+	chunks := s.chunker.Split(doc.Content)
+	for _, chunk := range chunks {
+		textData := strings.ReplaceAll(chunk.Text, "\n", " ")
+		computedTokens.ChunkedText = append(computedTokens.ChunkedText, textData)
+		computedTokens.Offsets = append(computedTokens.Offsets, chunk.Offset)
+	}
+
+	batchSize, concurrency := s.config.BatchSize, s.config.Concurrency
+	vectors, err := s.cache.Load(s.modelID(), computedTokens.ChunkedText, func(texts []string) ([][]float64, error) {
+		return embeddings.LoadBatches(ctx, texts, batchSize, concurrency, s.loadEmbeddings)
+	})
+	if err != nil {
+		return computedTokens, err
+	}
+
+	computedTokens.Chunks = len(chunks)
+	computedTokens.Vectors = vectors
+	if !s.config.IncludeChunkText {
+		computedTokens.DropChunkedText()
+	}
+
+	return computedTokens, nil
+}
+
+// Model identifies model with vectors dimensions requested from it.
+func (s *Service) Model(_ context.Context) (string, error) {
+	return s.modelID(), nil
+}
+
+// modelID identifies model with vectors dimensions requested from it.
+func (s *Service) modelID() string {
+	if s.config.OpenAI.Dimensions > 0 {
+		return fmt.Sprintf("%s/%d", s.config.OpenAI.Model, s.config.OpenAI.Dimensions)
+	}
+
+	return s.config.OpenAI.Model
+}
+
+func (s *Service) loadEmbeddings(ctx context.Context, batch []string) ([][]float64, error) {
+	embedForm := &embeddings.OpenAIEmbedForm{
+		Model:          s.config.OpenAI.Model,
+		Input:          batch,
+		Dimensions:     s.config.OpenAI.Dimensions,
+		EncodingFormat: "float",
+	}
+
+	jsonData, err := json.Marshal(embedForm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal form: %w", err)
+	}
+
+	log.Printf("sending batch of %d chunks to generate embeddings", len(batch))
+
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, EmbeddingsURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if len(s.config.OpenAI.ApiKey) > 0 {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+s.config.OpenAI.ApiKey)
+	}
+
+	respData, err := sender.SendRequest(s.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+
+	embedResp := &embeddings.OpenAIEmbedResponse{}
+	if err = json.Unmarshal(respData, embedResp); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+
+	// vectors may be returned in any order and are placed by their indexes
+	vectors := make([][]float64, len(batch))
+	for _, data := range embedResp.Data {
+		if data.Index < 0 || data.Index >= len(batch) {
+			return nil, fmt.Errorf("returned vector with unknown index %d", data.Index)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+
+	for index, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("returned no vector for chunk %d of batch", index)
+		}
+	}
+
+	return vectors, nil
+}
@@ -1,11 +1,17 @@
 package embeddings
 
-import "doc-watcher/internal/watcher"
+import (
+	"context"
+
+	"doc-watcher/internal/watcher"
+)
 
 type Service struct {
 	Tokenizer Tokenizer
 }
 
 type Tokenizer interface {
-	Tokenize(doc *watcher.Document) (*ComputeTokens, error)
+	Tokenize(ctx context.Context, doc *watcher.Document) (*ComputeTokens, error)
+	// Model identifies model computing vectors to detect stale vectors.
+	Model(ctx context.Context) (string, error)
 }
@@ -2,28 +2,50 @@ package sovavec
 
 import (
 	"bytes"
-	"doc-watcher/internal/watcher"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/embeddings/cache"
+	"doc-watcher/internal/embeddings/chunker"
 	"doc-watcher/internal/sender"
+	"doc-watcher/internal/watcher"
 	"github.com/labstack/echo/v4"
 )
 
-const EmbeddingsAssistantURL = "/embed"
+const (
+	BackendName            = "sova"
+	EmbeddingsAssistantURL = "/embed"
+	EmbeddingsChunksURL    = "/embed_chunks"
+	InfoURL                = "/info"
+)
+
+// requestTimeout is a timeout of single request to embeddings service.
+const requestTimeout = 300 * time.Second
 
 type Service struct {
-	config *embeddings.Config
+	config  *embeddings.Config
+	chunker chunker.Chunker
+	client  *http.Client
+	cache   *cache.Service
+
+	modelMu sync.Mutex
+	model   string
 }
 
-func New(config *embeddings.Config) *embeddings.Service {
+func New(config *embeddings.Config, cacheServ *cache.Service) *embeddings.Service {
 	servClient := &Service{
-		config: config,
+		config:  config,
+		chunker: chunker.New(config),
+		client:  &http.Client{Timeout: requestTimeout},
+		cache:   cacheServ,
+		model:   config.Model,
 	}
 
 	return &embeddings.Service{
@@ -31,78 +53,200 @@ func New(config *embeddings.Config) *embeddings.Service {
 	}
 }
 
-func (s *Service) Tokenize(doc *watcher.Document) (*embeddings.ComputeTokens, error) {
+func (s *Service) Tokenize(ctx context.Context, doc *watcher.Document) (*embeddings.ComputeTokens, error) {
+	var computedTokens *embeddings.ComputeTokens
+	var err error
+	if s.config.ChunkBySelf {
+		computedTokens, err = s.tokenizeByService(ctx, doc)
+	} else {
+		computedTokens, err = s.tokenizeByChunks(ctx, doc)
+	}
+
+	if err != nil {
+		return computedTokens, err
+	}
+
+	if !s.config.IncludeChunkText {
+		computedTokens.DropChunkedText()
+	}
+
+	return computedTokens, nil
+}
+
+func (s *Service) tokenizeByChunks(ctx context.Context, doc *watcher.Document) (*embeddings.ComputeTokens, error) {
 	computedTokens := &embeddings.ComputeTokens{
 		Chunks:      0,
 		ChunkedText: []string{},
+		Offsets:     []int{},
 		Vectors:     [][]float64{},
 	}
 
-	contentData := strings.ReplaceAll(doc.Content, "\n", " ")
-	chunkedText := s.splitContent(contentData, s.config.ChunkSize)
-	for _, textData := range chunkedText {
-		tokens, err := s.loadTextDataTokens(textData)
+	chunks := s.chunker.Split(doc.Content)
+	for _, chunk := range chunks {
+		textData := strings.ReplaceAll(chunk.Text, "\n", " ")
+		computedTokens.ChunkedText = append(computedTokens.ChunkedText, textData)
+		computedTokens.Offsets = append(computedTokens.Offsets, chunk.Offset)
+	}
+
+	model, err := s.Model(ctx)
+	if err != nil {
+		return computedTokens, err
+	}
+
+	batchSize, concurrency := s.config.BatchSize, s.config.Concurrency
+	vectors, err := s.cache.Load(model, computedTokens.ChunkedText, func(texts []string) ([][]float64, error) {
+		return embeddings.LoadBatches(ctx, texts, batchSize, concurrency, s.loadTextDataTokens)
+	})
+	if err != nil {
+		return computedTokens, err
+	}
+
+	computedTokens.Chunks = len(chunks)
+	computedTokens.Vectors = vectors
+	return computedTokens, nil
+}
+
+// Model identifies model served by embeddings service. Model which is not
+// set by config is requested from service on first use and requested again
+// after failure, so watcher is launched while service is not available.
+func (s *Service) Model(ctx context.Context) (string, error) {
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+
+	if len(s.model) == 0 {
+		model, err := s.fetchModel(ctx)
 		if err != nil {
-			log.Printf("failed to load embeddings for %s: %v", doc.DocumentName, err)
-			continue
+			return "", fmt.Errorf("failed to get embeddings model, set it by config: %w", err)
+		}
+		s.model = model
+	}
+
+	return BackendName + "/" + s.model, nil
+}
+
+// fetchModel requests name of model served by embeddings service.
+func (s *Service) fetchModel(ctx context.Context) (string, error) {
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, InfoURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	respData, err := sender.SendRequest(s.client, req)
+	if err != nil {
+		return "", err
+	}
+
+	info := &embeddings.InfoForm{}
+	if err = json.Unmarshal(respData, info); err != nil {
+		return "", fmt.Errorf("failed to decode service info: %w", err)
+	}
+
+	if len(info.ModelID) == 0 {
+		return "", fmt.Errorf("service info has no model id")
+	}
+
+	return info.ModelID, nil
+}
+
+// tokenizeByService sends whole content to embeddings service which splits
+// it into chunks and returns chunks boundaries with vectors.
+func (s *Service) tokenizeByService(ctx context.Context, doc *watcher.Document) (*embeddings.ComputeTokens, error) {
+	computedTokens := &embeddings.ComputeTokens{
+		Chunks:      0,
+		ChunkedText: []string{},
+		Offsets:     []int{},
+		Vectors:     [][]float64{},
+	}
+
+	chunksForm := &embeddings.EmbedChunksForm{
+		Inputs:       strings.ReplaceAll(doc.Content, "\n", " "),
+		ChunkSize:    s.config.ChunkSize,
+		ChunkOverlap: s.config.ChunkOverlap,
+		ReturnChunks: s.config.ReturnChunks,
+		Normalize:    true,
+	}
+
+	log.Printf("sending content to chunk and generate embeddings")
+
+	respData, err := s.post(ctx, EmbeddingsChunksURL, chunksForm)
+	if err != nil {
+		return computedTokens, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+
+	chunks := make([]*embeddings.ChunkForm, 0)
+	if err = json.Unmarshal(respData, &chunks); err != nil {
+		return computedTokens, fmt.Errorf("failed to decode chunks: %w", err)
+	}
+
+	runeOffsets := runeByteOffsets(chunksForm.Inputs)
+	for _, chunk := range chunks {
+		start := min(max(chunk.Start, 0), len(runeOffsets)-1)
+		end := min(max(chunk.End, start), len(runeOffsets)-1)
+
+		text := chunk.Text
+		if len(text) == 0 {
+			text = chunksForm.Inputs[runeOffsets[start]:runeOffsets[end]]
 		}
 
 		computedTokens.Chunks++
-		computedTokens.Vectors = append(computedTokens.Vectors, tokens)
-		computedTokens.ChunkedText = append(computedTokens.ChunkedText, textData)
+		computedTokens.Vectors = append(computedTokens.Vectors, chunk.Vector)
+		computedTokens.ChunkedText = append(computedTokens.ChunkedText, text)
+		computedTokens.Offsets = append(computedTokens.Offsets, runeOffsets[start])
 	}
 
 	return computedTokens, nil
 }
 
-func (s *Service) loadTextDataTokens(content string) ([]float64, error) {
+// runeByteOffsets returns byte offsets of each character and text end.
+func runeByteOffsets(text string) []int {
+	offsets := make([]int, 0, len(text)+1)
+	for index := range text {
+		offsets = append(offsets, index)
+	}
+
+	return append(offsets, len(text))
+}
+
+func (s *Service) loadTextDataTokens(ctx context.Context, batch []string) ([][]float64, error) {
 	textVectors := &embeddings.EmbedAllForm{
-		Inputs:    content,
+		Inputs:    batch,
 		Truncate:  false,
 		Normalize: true,
 	}
 
-	jsonData, err := json.Marshal(textVectors)
-	if err != nil {
-		return []float64{}, fmt.Errorf("failed to marshal tokens: %w", err)
-	}
+	log.Printf("sending batch of %d chunks to generate embeddings", len(batch))
 
-	log.Printf("sending chunk to generate embeddings")
-
-	reqBody := bytes.NewBuffer(jsonData)
-	mimeType := echo.MIMEApplicationJSON
-	timeoutReq := time.Duration(300) * time.Second
-	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, EmbeddingsAssistantURL)
-	respData, err := sender.POST(reqBody, targetURL, mimeType, timeoutReq)
+	respData, err := s.post(ctx, EmbeddingsAssistantURL, textVectors)
 	if err != nil {
-		return []float64{}, fmt.Errorf("failed to load embeddings: %w", err)
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
 	}
 
 	tokens := make([][]float64, 0)
-	_ = json.Unmarshal(respData, &tokens)
+	if err = json.Unmarshal(respData, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
 
-	if len(tokens) < 1 {
-		log.Println("returned empty tokens from tokenizer service")
-		return make([]float64, 0), nil
+	if len(tokens) != len(batch) {
+		return nil, fmt.Errorf("returned %d vectors for %d chunks", len(tokens), len(batch))
 	}
 
-	return tokens[0], nil
+	return tokens, nil
 }
 
-func (s *Service) splitContent(content string, chunkSize int) []string {
-	strLength := len(content)
-	splitLength := int(math.Ceil(float64(strLength) / float64(chunkSize)))
-	splitString := make([]string, splitLength)
-	var start, stop int
-	for i := 0; i < splitLength; i++ {
-		start = i * chunkSize
-		stop = start + chunkSize
-		if stop > strLength {
-			stop = strLength
-		}
+// post sends form to embeddings service by shared client.
+func (s *Service) post(ctx context.Context, path string, form any) ([]byte, error) {
+	jsonData, err := json.Marshal(form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal form: %w", err)
+	}
 
-		splitString[i] = content[start:stop]
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return splitString
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return sender.SendRequest(s.client, req)
 }
@@ -0,0 +1,203 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// wordPiece splits words into the longest vocabulary pieces like BERT.
+type wordPiece struct {
+	vocab    map[string]int
+	prefix   string
+	maxChars int
+}
+
+func newWordPiece(file *modelFile) (model, error) {
+	vocab := make(map[string]int)
+	if err := json.Unmarshal(file.Vocab, &vocab); err != nil {
+		return nil, fmt.Errorf("failed to decode wordpiece vocab: %w", err)
+	}
+
+	prefix := file.ContinuingSubwordPrefix
+	if len(prefix) == 0 {
+		prefix = "##"
+	}
+
+	maxChars := file.MaxInputCharsPerWord
+	if maxChars <= 0 {
+		maxChars = 100
+	}
+
+	return &wordPiece{vocab: vocab, prefix: prefix, maxChars: maxChars}, nil
+}
+
+func (m *wordPiece) count(word string) int {
+	runes := []rune(word)
+	if len(runes) > m.maxChars {
+		return 1
+	}
+
+	count := 0
+	for start := 0; start < len(runes); count++ {
+		end := len(runes)
+		for ; end > start; end-- {
+			piece := string(runes[start:end])
+			if start > 0 {
+				piece = m.prefix + piece
+			}
+			if _, ok := m.vocab[piece]; ok {
+				break
+			}
+		}
+
+		// word without vocabulary pieces is replaced by single unknown token
+		if end == start {
+			return 1
+		}
+		start = end
+	}
+
+	return count
+}
+
+// bpe merges characters of words by ranked merges like GPT-2.
+type bpe struct {
+	vocab        map[string]int
+	ranks        map[string]int
+	byteFallback bool
+}
+
+func newBPE(file *modelFile) (model, error) {
+	vocab := make(map[string]int)
+	if err := json.Unmarshal(file.Vocab, &vocab); err != nil {
+		return nil, fmt.Errorf("failed to decode bpe vocab: %w", err)
+	}
+
+	// merges are stored either as "a b" strings or as ["a", "b"] pairs
+	merges := make([]string, 0)
+	if err := json.Unmarshal(file.Merges, &merges); err != nil {
+		pairs := make([][]string, 0)
+		if err = json.Unmarshal(file.Merges, &pairs); err != nil {
+			return nil, fmt.Errorf("failed to decode bpe merges: %w", err)
+		}
+
+		for _, pair := range pairs {
+			merges = append(merges, strings.Join(pair, " "))
+		}
+	}
+
+	ranks := make(map[string]int, len(merges))
+	for rank, merge := range merges {
+		ranks[merge] = rank
+	}
+
+	return &bpe{vocab: vocab, ranks: ranks, byteFallback: file.ByteFallback}, nil
+}
+
+func (m *bpe) count(word string) int {
+	symbols := make([]string, 0, len(word))
+	for _, r := range word {
+		symbols = append(symbols, string(r))
+	}
+
+	for len(symbols) > 1 {
+		best, bestRank := -1, math.MaxInt
+		for index := 0; index < len(symbols)-1; index++ {
+			rank, ok := m.ranks[symbols[index]+" "+symbols[index+1]]
+			if ok && rank < bestRank {
+				best, bestRank = index, rank
+			}
+		}
+
+		if best < 0 {
+			break
+		}
+
+		left, right := symbols[best], symbols[best+1]
+		merged := make([]string, 0, len(symbols)-1)
+		for index := 0; index < len(symbols); index++ {
+			if index < len(symbols)-1 && symbols[index] == left && symbols[index+1] == right {
+				merged = append(merged, left+right)
+				index++
+				continue
+			}
+			merged = append(merged, symbols[index])
+		}
+		symbols = merged
+	}
+
+	count := 0
+	for _, symbol := range symbols {
+		if _, ok := m.vocab[symbol]; !ok && m.byteFallback {
+			count += len(symbol)
+			continue
+		}
+		count++
+	}
+
+	return count
+}
+
+// unigram splits words into pieces of the most probable segmentation
+// like sentencepiece.
+type unigram struct {
+	scores   map[string]float64
+	maxLen   int
+	unkScore float64
+}
+
+func newUnigram(file *modelFile) (model, error) {
+	vocab := make([][2]any, 0)
+	if err := json.Unmarshal(file.Vocab, &vocab); err != nil {
+		return nil, fmt.Errorf("failed to decode unigram vocab: %w", err)
+	}
+
+	model := &unigram{scores: make(map[string]float64, len(vocab))}
+
+	minScore := 0.0
+	for _, entry := range vocab {
+		piece, _ := entry[0].(string)
+		score, _ := entry[1].(float64)
+		model.scores[piece] = score
+		model.maxLen = max(model.maxLen, utf8.RuneCountInString(piece))
+		minScore = min(minScore, score)
+	}
+
+	model.unkScore = minScore - 10
+	return model, nil
+}
+
+func (m *unigram) count(word string) int {
+	runes := []rune(word)
+
+	scores := make([]float64, len(runes)+1)
+	counts := make([]int, len(runes)+1)
+	for index := 1; index <= len(runes); index++ {
+		scores[index] = math.Inf(-1)
+	}
+
+	for start := 0; start < len(runes); start++ {
+		if math.IsInf(scores[start], -1) {
+			continue
+		}
+
+		// unknown character is always a valid single piece
+		if scores[start]+m.unkScore > scores[start+1] {
+			scores[start+1] = scores[start] + m.unkScore
+			counts[start+1] = counts[start] + 1
+		}
+
+		for end := start + 1; end <= min(start+m.maxLen, len(runes)); end++ {
+			score, ok := m.scores[string(runes[start:end])]
+			if ok && scores[start]+score > scores[end] {
+				scores[end] = scores[start] + score
+				counts[end] = counts[start] + 1
+			}
+		}
+	}
+
+	return counts[len(runes)]
+}
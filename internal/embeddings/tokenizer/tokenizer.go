@@ -0,0 +1,294 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	ModelWordPiece = "WordPiece"
+	ModelBPE       = "BPE"
+	ModelUnigram   = "Unigram"
+)
+
+// metaspace replaces spaces of sentencepiece based tokenizers.
+const metaspace = "▁"
+
+type preTokenizeMode int
+
+const (
+	// splitPunctuation splits words by whitespaces and punctuation like BERT.
+	splitPunctuation preTokenizeMode = iota
+	// splitMetaspace prefixes each word by metaspace like sentencepiece.
+	splitMetaspace
+	// splitByteLevel splits words by letters, digits and other characters
+	// and maps their bytes to printable characters like GPT-2.
+	splitByteLevel
+)
+
+// model counts tokens of pre-tokenized word.
+type model interface {
+	count(word string) int
+}
+
+// Tokenizer counts tokens of text by local HuggingFace tokenizer.json
+// vocabulary. It approximates normalization and pre-tokenization of
+// original tokenizer and does not count special tokens.
+type Tokenizer struct {
+	model        model
+	mode         preTokenizeMode
+	lowercase    bool
+	stripAccents bool
+	compose      bool
+}
+
+type tokenizerFile struct {
+	Normalizer   *component `json:"normalizer"`
+	PreTokenizer *component `json:"pre_tokenizer"`
+	Model        modelFile  `json:"model"`
+}
+
+type component struct {
+	Type          string       `json:"type"`
+	Lowercase     *bool        `json:"lowercase"`
+	StripAccents  *bool        `json:"strip_accents"`
+	Normalizers   []*component `json:"normalizers"`
+	PreTokenizers []*component `json:"pretokenizers"`
+}
+
+type modelFile struct {
+	Type                    string          `json:"type"`
+	Vocab                   json.RawMessage `json:"vocab"`
+	Merges                  json.RawMessage `json:"merges"`
+	UnkToken                string          `json:"unk_token"`
+	UnkID                   *int            `json:"unk_id"`
+	ContinuingSubwordPrefix string          `json:"continuing_subword_prefix"`
+	MaxInputCharsPerWord    int             `json:"max_input_chars_per_word"`
+	ByteFallback            bool            `json:"byte_fallback"`
+}
+
+// Load reads tokenizer from HuggingFace tokenizer.json file.
+func Load(path string) (*Tokenizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokenizer file: %w", err)
+	}
+
+	file := &tokenizerFile{}
+	if err = json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to decode tokenizer file: %w", err)
+	}
+
+	tokenizer := &Tokenizer{}
+	if tokenizer.model, err = loadModel(&file.Model); err != nil {
+		return nil, err
+	}
+
+	normalizers := flatten(file.Normalizer)
+	for _, normalizer := range normalizers {
+		switch normalizer.Type {
+		case "BertNormalizer":
+			lowercase := normalizer.Lowercase == nil || *normalizer.Lowercase
+			tokenizer.lowercase = tokenizer.lowercase || lowercase
+			if normalizer.StripAccents != nil {
+				tokenizer.stripAccents = tokenizer.stripAccents || *normalizer.StripAccents
+			} else {
+				tokenizer.stripAccents = tokenizer.stripAccents || lowercase
+			}
+		case "Lowercase":
+			tokenizer.lowercase = true
+		case "StripAccents":
+			tokenizer.stripAccents = true
+		case "NFC", "NFKC", "Precompiled":
+			tokenizer.compose = true
+		case "Prepend":
+			tokenizer.mode = splitMetaspace
+		}
+	}
+
+	for _, preTokenizer := range flatten(file.PreTokenizer) {
+		switch preTokenizer.Type {
+		case "ByteLevel":
+			tokenizer.mode = splitByteLevel
+		case "Metaspace":
+			tokenizer.mode = splitMetaspace
+		}
+	}
+
+	return tokenizer, nil
+}
+
+func loadModel(file *modelFile) (model, error) {
+	modelType := file.Type
+	if len(modelType) == 0 {
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(string(file.Vocab)), "["):
+			modelType = ModelUnigram
+		case len(file.Merges) > 0:
+			modelType = ModelBPE
+		default:
+			modelType = ModelWordPiece
+		}
+	}
+
+	switch modelType {
+	case ModelWordPiece:
+		return newWordPiece(file)
+	case ModelBPE:
+		return newBPE(file)
+	case ModelUnigram:
+		return newUnigram(file)
+	default:
+		return nil, fmt.Errorf("unsupported tokenizer model: %s", modelType)
+	}
+}
+
+// flatten returns component with its nested sequence components.
+func flatten(root *component) []*component {
+	if root == nil {
+		return nil
+	}
+
+	components := []*component{root}
+	for _, nested := range append(root.Normalizers, root.PreTokenizers...) {
+		components = append(components, flatten(nested)...)
+	}
+
+	return components
+}
+
+// Count returns number of tokens of text.
+func (t *Tokenizer) Count(text string) int {
+	count := 0
+	for _, word := range t.preTokenize(t.normalize(text)) {
+		count += t.model.count(word)
+	}
+
+	return count
+}
+
+func (t *Tokenizer) normalize(text string) string {
+	if t.lowercase {
+		text = strings.ToLower(text)
+	}
+
+	if t.stripAccents {
+		text = strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Mn, r) {
+				return -1
+			}
+			return r
+		}, norm.NFD.String(text))
+	}
+
+	if t.compose {
+		text = norm.NFKC.String(text)
+	}
+
+	return text
+}
+
+func (t *Tokenizer) preTokenize(text string) []string {
+	words := make([]string, 0)
+	for _, field := range strings.Fields(text) {
+		switch t.mode {
+		case splitMetaspace:
+			words = append(words, metaspace+field)
+		case splitByteLevel:
+			for index, group := range splitGroups(field) {
+				if index == 0 {
+					group = " " + group
+				}
+				words = append(words, mapBytes(group))
+			}
+		default:
+			words = append(words, splitPunct(field)...)
+		}
+	}
+
+	return words
+}
+
+// splitPunct splits punctuation and CJK characters of word into separate words.
+func splitPunct(field string) []string {
+	words := make([]string, 0, 1)
+
+	start := 0
+	for index, r := range field {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) && !unicode.Is(unicode.Han, r) {
+			continue
+		}
+
+		if index > start {
+			words = append(words, field[start:index])
+		}
+		words = append(words, string(r))
+		start = index + len(string(r))
+	}
+
+	if start < len(field) {
+		words = append(words, field[start:])
+	}
+
+	return words
+}
+
+// splitGroups splits word into runs of letters, digits and other characters.
+func splitGroups(field string) []string {
+	groups := make([]string, 0, 1)
+
+	start, previous := 0, -1
+	for index, r := range field {
+		class := runeClass(r)
+		if previous >= 0 && class != previous {
+			groups = append(groups, field[start:index])
+			start = index
+		}
+		previous = class
+	}
+
+	return append(groups, field[start:])
+}
+
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsLetter(r):
+		return 0
+	case unicode.IsDigit(r):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// byteRunes maps bytes to printable characters of byte level BPE.
+var byteRunes = func() [256]rune {
+	var runes [256]rune
+
+	shift := rune(0)
+	for value := 0; value < 256; value++ {
+		printable := (value >= '!' && value <= '~') || (value >= 0xA1 && value <= 0xAC) || (value >= 0xAE && value <= 0xFF)
+		if printable {
+			runes[value] = rune(value)
+		} else {
+			runes[value] = 256 + shift
+			shift++
+		}
+	}
+
+	return runes
+}()
+
+func mapBytes(text string) string {
+	var builder strings.Builder
+	for index := 0; index < len(text); index++ {
+		builder.WriteRune(byteRunes[text[index]])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,92 @@
+package tokenizer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		fixture string
+		text    string
+		count   int
+	}{
+		{"wordpiece.json", "", 0},
+		{"wordpiece.json", "Hello, world!", 4},
+		{"wordpiece.json", "unwanted", 3},
+		{"wordpiece.json", "Héllo", 1},
+		{"wordpiece.json", "xyz", 1},
+		{"wordpiece.json", "Привет", 2},
+		{"wordpiece.json", "東京", 2},
+		{"bpe-bytelevel.json", "hello", 1},
+		{"bpe-bytelevel.json", "hello world", 7},
+		{"bpe-bytelevel.json", "hello12", 3},
+		{"bpe-bytelevel.json", "да", 5},
+		{"bpe-metaspace.json", "hi", 1},
+		{"bpe-metaspace.json", "hi hi", 2},
+		{"bpe-metaspace.json", "hi я", 4},
+		{"unigram.json", "hello world", 2},
+		{"unigram.json", "ｈｅｌｌｏ", 1},
+		{"unigram.json", "worm", 5},
+	}
+
+	tokenizers := make(map[string]*Tokenizer)
+	for _, test := range tests {
+		t.Run(test.fixture+"/"+test.text, func(t *testing.T) {
+			tokenizer, ok := tokenizers[test.fixture]
+			if !ok {
+				var err error
+				if tokenizer, err = Load(filepath.Join("testdata", test.fixture)); err != nil {
+					t.Fatalf("failed to load tokenizer: %v", err)
+				}
+				tokenizers[test.fixture] = tokenizer
+			}
+
+			if count := tokenizer.Count(test.text); count != test.count {
+				t.Errorf("count = %d, want %d", count, test.count)
+			}
+		})
+	}
+}
+
+func TestLoadModelType(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		model   model
+		wantErr bool
+	}{
+		{name: "wordpiece by default", data: `{"model": {"vocab": {"a": 0}}}`, model: &wordPiece{}},
+		{name: "bpe by merges", data: `{"model": {"vocab": {"a": 0}, "merges": []}}`, model: &bpe{}},
+		{name: "unigram by vocab list", data: `{"model": {"vocab": [["a", -1.0]]}}`, model: &unigram{}},
+		{name: "unsupported model", data: `{"model": {"type": "WordLevel", "vocab": {}}}`, wantErr: true},
+		{name: "malformed file", data: `{"model": `, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokenizer.json")
+			if err := os.WriteFile(path, []byte(test.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			tokenizer, err := Load(path)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if reflect.TypeOf(tokenizer.model) != reflect.TypeOf(test.model) {
+				t.Errorf("model = %T, want %T", tokenizer.model, test.model)
+			}
+		})
+	}
+}
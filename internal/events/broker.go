@@ -0,0 +1,79 @@
+package events
+
+import (
+	"sync"
+
+	"doc-watcher/internal/watcher"
+)
+
+// Broker delivers document stage events to all subscribers.
+type Broker struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]*Subscription
+	handlers    []func(event *watcher.StageEvent)
+}
+
+type Subscription struct {
+	id     int
+	Events chan *watcher.StageEvent
+}
+
+func New() *Broker {
+	return &Broker{
+		subscribers: make(map[int]*Subscription),
+	}
+}
+
+// Subscribe creates subscription with passed events buffer size.
+func (b *Broker) Subscribe(bufferSize int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &Subscription{
+		id:     b.nextID,
+		Events: make(chan *watcher.StageEvent, bufferSize),
+	}
+
+	b.subscribers[sub.id] = sub
+	return sub
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub.id]; ok {
+		delete(b.subscribers, sub.id)
+		close(sub.Events)
+	}
+}
+
+// Handle registers function called synchronously with each published
+// event, so events are never dropped for it. Handler delays publisher,
+// so it must not block for long.
+func (b *Broker) Handle(handler func(event *watcher.StageEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish passes event to handlers and sends it to subscribers without
+// blocking. Event is dropped for subscribers which buffer is full.
+func (b *Broker) Publish(event *watcher.StageEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.Events <- event:
+		default:
+		}
+	}
+}
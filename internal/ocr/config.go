@@ -6,4 +6,67 @@ type Config struct {
 	Address   string
 	EnableSSL bool
 	Timeout   time.Duration
+
+	// DefaultRecognizer handles documents which are not matched by routes.
+	DefaultRecognizer string
+	// FallbackRecognizer handles documents without text for native extraction.
+	FallbackRecognizer string
+	Routes             []*Route
+
+	// QualityThreshold is a minimal quality score from 0 to 10000 of
+	// recognized documents, other documents are flagged as low quality.
+	QualityThreshold int32
+
+	Async     AsyncConfig
+	Pages     PagesConfig
+	Tesseract TesseractConfig
+	Native    NativeConfig
+}
+
+type NativeConfig struct {
+	// MaxDecodedSize is a maximal size in megabytes of data decompressed
+	// from one document like pdf streams and office archives files.
+	MaxDecodedSize int64
+}
+
+// PagesConfig enables splitting of multipage documents like PDF and TIFF
+// into pages which are recognized concurrently.
+type PagesConfig struct {
+	Enabled     bool
+	Concurrency int
+	Dpi         int
+	PdfCommand  string
+	TiffCommand string
+}
+
+// AsyncConfig enables recognition by submitted OCR jobs which are polled
+// until completion instead of waiting for synchronous response.
+type AsyncConfig struct {
+	Enabled bool
+	// PollInterval and MaxPollInterval are intervals between polls
+	// in milliseconds, it grows by Multiplier after each poll.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+	Multiplier      float64
+	// JobTimeout is a maximal duration of OCR job in seconds.
+	JobTimeout time.Duration
+}
+
+type TesseractConfig struct {
+	Command    string
+	PdfCommand string
+	Languages  []string
+	Dpi        int
+	Timeout    time.Duration
+}
+
+// Route dispatches documents to recognizer by name. Document is matched
+// when all filled criteria are satisfied by any of their values.
+type Route struct {
+	Recognizer string   `json:"recognizer"`
+	MimeTypes  []string `json:"mime_types"`
+	Extensions []string `json:"extensions"`
+	Buckets    []string `json:"buckets"`
+	MinSize    int64    `json:"min_size"`
+	MaxSize    int64    `json:"max_size"`
 }
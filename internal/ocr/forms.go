@@ -1,5 +1,44 @@
 package ocr
 
+import "doc-watcher/internal/watcher"
+
+type JobStatus string
+
+const (
+	JobPending  JobStatus = "pending"
+	JobRunning  JobStatus = "running"
+	JobDone     JobStatus = "done"
+	JobFailed   JobStatus = "failed"
+	JobCanceled JobStatus = "canceled"
+)
+
+// JobForm is a state of asynchronous recognition job.
+type JobForm struct {
+	JobID  string        `json:"job_id"`
+	Status JobStatus     `json:"status"`
+	Error  string        `json:"error"`
+	Result *DocumentForm `json:"result"`
+}
+
+// DocumentForm is a recognition result of document returned by OCR service.
 type DocumentForm struct {
-	Content string `json:"text"`
+	JobID      string               `json:"job_id"`
+	Content    string               `json:"text"`
+	PagesCount int                  `json:"pages_count"`
+	DocType    string               `json:"doc_type"`
+	Artifacts  []*watcher.Artifacts `json:"artifacts"`
+}
+
+// OcrMetadata converts recognition result to document ocr metadata. Text is
+// not duplicated into metadata because it is stored as document content.
+func (f *DocumentForm) OcrMetadata() *watcher.OcrMetadata {
+	ocrMetadata := watcher.DefaultOcr()
+	ocrMetadata.JobId = f.JobID
+	ocrMetadata.PagesCount = max(f.PagesCount, 1)
+	ocrMetadata.DocType = f.DocType
+	if f.Artifacts != nil {
+		ocrMetadata.Artifacts = f.Artifacts
+	}
+
+	return ocrMetadata
 }
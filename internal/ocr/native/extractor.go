@@ -0,0 +1,192 @@
+package native
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported document format")
+
+type Extracted struct {
+	Text       string
+	PagesCount int
+}
+
+// ExtractText extracts text of digital document by file extension. Data
+// decompressed from document is bounded by maxDecoded size in bytes.
+func ExtractText(extension string, data []byte, maxDecoded int64) (*Extracted, error) {
+	var text string
+	var err error
+
+	limit := newDecodeLimit(maxDecoded)
+
+	pagesCount := 1
+	switch strings.ToLower(extension) {
+	case ".txt", ".text", ".csv", ".tsv", ".json", ".md", ".log":
+		text = decodePlainText(data)
+	case ".html", ".htm", ".xhtml":
+		text, err = extractHtml(data)
+	case ".xml":
+		text, err = extractXmlText(bytes.NewReader(data), &xmlTextOptions{})
+	case ".docx":
+		text, err = extractDocx(data, limit)
+	case ".xlsx":
+		text, err = extractXlsx(data, limit)
+	case ".pptx":
+		text, pagesCount, err = extractPptx(data, limit)
+	case ".odt", ".ods", ".odp":
+		text, err = extractOdf(data, limit)
+	case ".pdf":
+		text, pagesCount, err = extractPdf(data, limit)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Extracted{Text: normalizeText(text), PagesCount: pagesCount}, nil
+}
+
+// decodePlainText returns text of UTF-8 data or decodes it from Windows-1251
+// which is commonly used for plain text documents in Russian.
+func decodePlainText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "")
+	}
+
+	return string(decoded)
+}
+
+func extractHtml(data []byte) (string, error) {
+	opts := &xmlTextOptions{
+		HTML:          true,
+		SkipElements:  setOf("script", "style", "noscript", "svg"),
+		BreakElements: setOf("p", "div", "br", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "title", "table"),
+		TabElements:   setOf("td", "th"),
+	}
+
+	return extractXmlText(bytes.NewReader(data), opts)
+}
+
+type xmlTextOptions struct {
+	// HTML enables non-strict parsing of html documents.
+	HTML bool
+	// TextElements limits collected char data to these elements.
+	TextElements map[string]bool
+	// SkipElements are elements which content is ignored.
+	SkipElements map[string]bool
+	// BreakElements are elements which end is written as newline.
+	BreakElements map[string]bool
+	// TabElements are elements which start is written as tab.
+	TabElements map[string]bool
+	// SpaceElements are elements which start is written as space.
+	SpaceElements map[string]bool
+}
+
+// extractXmlText walks xml tokens and collects char data of elements
+// by local names without namespaces.
+func extractXmlText(reader io.Reader, opts *xmlTextOptions) (string, error) {
+	decoder := xml.NewDecoder(reader)
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	if opts.HTML {
+		decoder.Strict = false
+		decoder.AutoClose = xml.HTMLAutoClose
+		decoder.Entity = xml.HTMLEntity
+	}
+
+	var builder strings.Builder
+	textDepth, skipDepth := 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			if opts.HTML && builder.Len() > 0 {
+				break
+			}
+			return "", err
+		}
+
+		switch elem := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(elem.Name.Local)
+			switch {
+			case opts.SkipElements[name]:
+				skipDepth++
+			case opts.TextElements[name]:
+				textDepth++
+			case opts.TabElements[name]:
+				builder.WriteByte('\t')
+			case opts.SpaceElements[name]:
+				builder.WriteByte(' ')
+			}
+
+		case xml.EndElement:
+			name := strings.ToLower(elem.Name.Local)
+			switch {
+			case opts.SkipElements[name]:
+				skipDepth = max(skipDepth-1, 0)
+			case opts.TextElements[name]:
+				textDepth = max(textDepth-1, 0)
+			case opts.BreakElements[name]:
+				builder.WriteByte('\n')
+			}
+
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+
+			if len(opts.TextElements) > 0 && textDepth == 0 {
+				continue
+			}
+
+			builder.Write(elem)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// normalizeText collapses repeated spaces and empty lines.
+func normalizeText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if len(line) == 0 {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+
+	return set
+}
@@ -0,0 +1,55 @@
+package native
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrDecodedTooLarge is returned when data decompressed from document
+// exceeds configured limit, so compressed bombs do not exhaust memory.
+var ErrDecodedTooLarge = errors.New("decompressed document data exceeds limit")
+
+// decodeLimit bounds total size of data decompressed from one document.
+// Limit which is not positive does not bound decompressed data.
+type decodeLimit struct {
+	remaining int64
+	unbounded bool
+}
+
+func newDecodeLimit(limit int64) *decodeLimit {
+	return &decodeLimit{remaining: limit, unbounded: limit <= 0}
+}
+
+// wrap returns reader which fails when limit has been exceeded.
+func (l *decodeLimit) wrap(reader io.Reader) io.Reader {
+	if l.unbounded {
+		return reader
+	}
+
+	return &limitedReader{reader: reader, limit: l}
+}
+
+type limitedReader struct {
+	reader io.Reader
+	limit  *decodeLimit
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	// One byte over limit is read to tell exceeded limit from end of data.
+	if int64(len(p)) > r.limit.remaining+1 {
+		p = p[:r.limit.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+	r.limit.remaining -= int64(n)
+	if r.limit.remaining < 0 {
+		return n, ErrDecodedTooLarge
+	}
+
+	return n, err
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
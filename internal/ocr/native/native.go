@@ -0,0 +1,83 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"unicode"
+
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/ocr/quality"
+	"doc-watcher/internal/watcher"
+)
+
+const RecognizerName = "native"
+
+// minTextLetters is a minimal count of letters into extracted text to
+// consider document as digital one. Otherwise, it will be recognized by OCR.
+const minTextLetters = 16
+
+// maxReplacementRatio is a maximal ratio of replacement characters to
+// letters of extracted text, text layer with more undecoded characters
+// is considered as broken one and document is recognized by OCR.
+const maxReplacementRatio = 0.05
+
+// Service extracts text from digital documents natively and sends other
+// documents like scanned images to fallback recognizer.
+type Service struct {
+	config   *ocr.Config
+	fallback ocr.Recognizer
+}
+
+func New(config *ocr.Config, fallback *ocr.Service) *ocr.Service {
+	servClient := &Service{config: config}
+	if fallback != nil {
+		servClient.fallback = fallback.Ocr
+	}
+
+	return &ocr.Service{
+		Ocr: servClient,
+	}
+}
+
+func (s *Service) RecognizeFile(ctx context.Context, document *watcher.Document, data []byte) error {
+	if document.DocumentType == "document" {
+		maxDecoded := s.config.Native.MaxDecodedSize << 20
+		extracted, err := ExtractText(document.DocumentExtension, data, maxDecoded)
+		if err == nil && hasText(extracted.Text) {
+			ocrMetadata := watcher.DefaultOcr()
+			ocrMetadata.PagesCount = max(extracted.PagesCount, 1)
+			ocrMetadata.Recognizer = RecognizerName
+
+			document.SetContentData(extracted.Text)
+			document.SetOcrMetadata(ocrMetadata)
+			document.SetQuality(quality.Estimate(extracted.Text, nil))
+			return nil
+		}
+
+		if err != nil && !errors.Is(err, ErrUnsupportedFormat) {
+			log.Printf("failed to extract text of %s natively: %v", document.DocumentName, err)
+		}
+	}
+
+	if s.fallback == nil {
+		return fmt.Errorf("failed to extract text of %s natively", document.DocumentName)
+	}
+
+	return s.fallback.RecognizeFile(ctx, document, data)
+}
+
+func hasText(text string) bool {
+	letters, replaced := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+		case r == unicode.ReplacementChar:
+			replaced++
+		}
+	}
+
+	return letters >= minTextLetters && float64(replaced) <= float64(letters)*maxReplacementRatio
+}
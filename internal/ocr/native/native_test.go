@@ -0,0 +1,30 @@
+package native
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHasText(t *testing.T) {
+	paragraph := strings.Repeat("Съешь же ещё этих мягких французских булок. ", 4)
+
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "digital text", text: paragraph, want: true},
+		{name: "too few letters", text: "Page 1 of 12", want: false},
+		{name: "single replacement char", text: paragraph + "�", want: true},
+		{name: "broken text layer", text: strings.Repeat("ab�� ", 20), want: false},
+		{name: "empty text", text: "", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := hasText(test.text); got != test.want {
+				t.Errorf("hasText = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package native
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var slideNameRegex = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+var sheetNameRegex = regexp.MustCompile(`^xl/worksheets/sheet(\d+)\.xml$`)
+
+func extractDocx(data []byte, limit *decodeLimit) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx archive: %w", err)
+	}
+
+	opts := &xmlTextOptions{
+		TextElements:  setOf("t"),
+		BreakElements: setOf("p", "br", "tr"),
+		TabElements:   setOf("tab", "tc"),
+	}
+
+	return extractArchiveXml(archive, "word/document.xml", opts, limit)
+}
+
+func extractPptx(data []byte, limit *decodeLimit) (string, int, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open pptx archive: %w", err)
+	}
+
+	opts := &xmlTextOptions{
+		TextElements:  setOf("t"),
+		BreakElements: setOf("p"),
+	}
+
+	slides := sortedArchiveFiles(archive, slideNameRegex)
+	texts := make([]string, 0, len(slides))
+	for _, slide := range slides {
+		text, err := extractArchiveXml(archive, slide, opts, limit)
+		if err != nil {
+			return "", 0, err
+		}
+		texts = append(texts, text)
+	}
+
+	return strings.Join(texts, "\n"), len(slides), nil
+}
+
+func extractXlsx(data []byte, limit *decodeLimit) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open xlsx archive: %w", err)
+	}
+
+	sharedStrings, err := loadSharedStrings(archive, limit)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	for _, sheet := range sortedArchiveFiles(archive, sheetNameRegex) {
+		reader, err := openArchiveFile(archive, sheet, limit)
+		if err != nil {
+			return "", err
+		}
+
+		err = readSheetCells(reader, sharedStrings, &builder)
+		_ = reader.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse sheet %s: %w", sheet, err)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+func extractOdf(data []byte, limit *decodeLimit) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open odf archive: %w", err)
+	}
+
+	opts := &xmlTextOptions{
+		SkipElements:  setOf("automatic-styles", "font-face-decls", "annotation"),
+		BreakElements: setOf("p", "h", "line-break", "table-row"),
+		TabElements:   setOf("tab", "table-cell"),
+		SpaceElements: setOf("s"),
+	}
+
+	return extractArchiveXml(archive, "content.xml", opts, limit)
+}
+
+// loadSharedStrings loads xlsx shared strings table referenced by cells.
+func loadSharedStrings(archive *zip.Reader, limit *decodeLimit) ([]string, error) {
+	reader, err := openArchiveFile(archive, "xl/sharedStrings.xml", limit)
+	if errors.Is(err, ErrUnsupportedFormat) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	values := make([]string, 0)
+	decoder := xml.NewDecoder(reader)
+
+	var current strings.Builder
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse shared strings: %w", err)
+		}
+
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch elem.Name.Local {
+			case "si":
+				values = append(values, current.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(elem)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// readSheetCells writes sheet rows as lines with tab separated cells.
+func readSheetCells(reader io.Reader, sharedStrings []string, builder *strings.Builder) error {
+	decoder := xml.NewDecoder(reader)
+
+	var cellType string
+	var value strings.Builder
+	inValue := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "c":
+				cellType = ""
+				value.Reset()
+				for _, attr := range elem.Attr {
+					if attr.Name.Local == "t" {
+						cellType = attr.Value
+					}
+				}
+			case "v", "t":
+				inValue = true
+			}
+
+		case xml.EndElement:
+			switch elem.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				cellValue := value.String()
+				if cellType == "s" {
+					index, err := strconv.Atoi(strings.TrimSpace(cellValue))
+					if err == nil && index >= 0 && index < len(sharedStrings) {
+						cellValue = sharedStrings[index]
+					}
+				}
+				builder.WriteString(cellValue)
+				builder.WriteByte('\t')
+			case "row":
+				builder.WriteByte('\n')
+			}
+
+		case xml.CharData:
+			if inValue {
+				value.Write(elem)
+			}
+		}
+	}
+}
+
+func extractArchiveXml(archive *zip.Reader, name string, opts *xmlTextOptions, limit *decodeLimit) (string, error) {
+	reader, err := openArchiveFile(archive, name, limit)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+
+	text, err := extractXmlText(reader, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return text, nil
+}
+
+// openArchiveFile opens archive file which decompressed data is bounded
+// by limit shared by all files of archive.
+func openArchiveFile(archive *zip.Reader, name string, limit *decodeLimit) (io.ReadCloser, error) {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		return &limitedReadCloser{Reader: limit.wrap(reader), Closer: reader}, nil
+	}
+
+	return nil, fmt.Errorf("%w: archive has no %s", ErrUnsupportedFormat, name)
+}
+
+// sortedArchiveFiles returns archive files matched by numbered regex
+// sorted by number like slide1.xml, slide2.xml, ..., slide10.xml.
+func sortedArchiveFiles(archive *zip.Reader, nameRegex *regexp.Regexp) []string {
+	type numbered struct {
+		name   string
+		number int
+	}
+
+	files := make([]numbered, 0)
+	for _, file := range archive.File {
+		matches := nameRegex.FindStringSubmatch(file.Name)
+		if matches == nil {
+			continue
+		}
+
+		number, _ := strconv.Atoi(matches[1])
+		files = append(files, numbered{name: file.Name, number: number})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].number < files[j].number
+	})
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.name)
+	}
+
+	return names
+}
@@ -0,0 +1,155 @@
+package native
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func buildArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = file.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestExtractOfficeDocuments(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		files     map[string]string
+		text      string
+		pages     int
+	}{
+		{
+			name:      "docx paragraphs and tables",
+			extension: ".docx",
+			files: map[string]string{
+				"word/document.xml": `<w:document xmlns:w="w"><w:body>` +
+					`<w:p><w:r><w:t>Первый</w:t></w:r><w:r><w:t xml:space="preserve"> абзац</w:t></w:r></w:p>` +
+					`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>A1</w:t></w:r></w:p></w:tc>` +
+					`<w:tc><w:p><w:r><w:t>B1</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+					`</w:body></w:document>`,
+			},
+			text:  "Первый абзац\nA1\nB1",
+			pages: 1,
+		},
+		{
+			name:      "pptx slides in numeric order",
+			extension: ".pptx",
+			files: map[string]string{
+				"ppt/slides/slide10.xml": `<p:sld xmlns:a="a" xmlns:p="p"><a:p><a:r><a:t>Tenth</a:t></a:r></a:p></p:sld>`,
+				"ppt/slides/slide2.xml":  `<p:sld xmlns:a="a" xmlns:p="p"><a:p><a:r><a:t>Second</a:t></a:r></a:p></p:sld>`,
+			},
+			text:  "Second\nTenth",
+			pages: 2,
+		},
+		{
+			name:      "xlsx shared strings",
+			extension: ".xlsx",
+			files: map[string]string{
+				"xl/sharedStrings.xml": `<sst><si><t>Name</t></si><si><t>Иван</t></si></sst>`,
+				"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+					`<row><c t="s"><v>0</v></c><c><v>42</v></c></row>` +
+					`<row><c t="s"><v>1</v></c><c t="inlineStr"><is><t>inline</t></is></c></row>` +
+					`</sheetData></worksheet>`,
+			},
+			text:  "Name 42\nИван inline",
+			pages: 1,
+		},
+		{
+			name:      "odt skips styles",
+			extension: ".odt",
+			files: map[string]string{
+				"content.xml": `<office:document-content xmlns:office="o" xmlns:text="t">` +
+					`<office:automatic-styles><style>hidden</style></office:automatic-styles>` +
+					`<office:body><text:p>Hello<text:s/>world</text:p></office:body></office:document-content>`,
+			},
+			text:  "Hello world",
+			pages: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			extracted, err := ExtractText(test.extension, buildArchive(t, test.files), 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if extracted.Text != test.text {
+				t.Errorf("text = %q, want %q", extracted.Text, test.text)
+			}
+
+			if extracted.PagesCount != test.pages {
+				t.Errorf("pages = %d, want %d", extracted.PagesCount, test.pages)
+			}
+		})
+	}
+}
+
+func TestExtractOfficeMalformed(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		data      []byte
+		err       error
+	}{
+		{name: "not an archive", extension: ".docx", data: []byte("plain text")},
+		{
+			name:      "archive without document",
+			extension: ".docx",
+			data:      buildArchive(t, map[string]string{"word/other.xml": "<a/>"}),
+			err:       ErrUnsupportedFormat,
+		},
+		{
+			name:      "broken xml",
+			extension: ".docx",
+			data:      buildArchive(t, map[string]string{"word/document.xml": "<w:document><w:t>text"}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ExtractText(test.extension, test.data, 0)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestExtractOfficeDecodeLimit(t *testing.T) {
+	document := `<w:document><w:t>` + strings.Repeat("a", 64<<10) + `</w:t></w:document>`
+	data := buildArchive(t, map[string]string{"word/document.xml": document})
+
+	if _, err := ExtractText(".docx", data, 1<<10); !errors.Is(err, ErrDecodedTooLarge) {
+		t.Fatalf("error = %v, want %v", err, ErrDecodedTooLarge)
+	}
+
+	if _, err := ExtractText(".docx", data, int64(len(document))); err != nil {
+		t.Fatalf("unexpected error for file of limit size: %v", err)
+	}
+}
@@ -0,0 +1,520 @@
+package native
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	pdfObjectRegex    = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfStreamRegex    = regexp.MustCompile(`\bstream\r?\n`)
+	pdfLengthRegex    = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	pdfRefRegex       = regexp.MustCompile(`(\d+)\s+\d+\s+R`)
+	pdfNamedRefRegex  = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R`)
+	pdfRootRegex      = regexp.MustCompile(`/Root\s+(\d+)\s+\d+\s+R`)
+	pdfPageTypeRegex  = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfFlateOnlyRegex = regexp.MustCompile(`/Filter\s*(/FlateDecode|\[\s*/FlateDecode\s*\])`)
+	pdfHexRegex       = regexp.MustCompile(`<([0-9A-Fa-f\s]*)>`)
+)
+
+// pdfDocument is a minimal pdf reader to pull text layer of digital pdf
+// documents. It supports flate streams, object streams and ToUnicode maps
+// of fonts, which is enough for documents produced by office software.
+type pdfDocument struct {
+	objects map[int][]byte
+	streams map[int][]byte
+	cmaps   map[int]*pdfCMap
+}
+
+func extractPdf(data []byte, limit *decodeLimit) (string, int, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data[:min(len(data), 1024)]), []byte("%PDF")) {
+		return "", 0, fmt.Errorf("document has no pdf header")
+	}
+
+	doc, err := parsePdf(data, limit)
+	if err != nil {
+		return "", 0, err
+	}
+
+	pages := doc.pages(data)
+	if len(pages) == 0 {
+		return "", 0, fmt.Errorf("pdf document has no pages")
+	}
+
+	texts := make([]string, 0, len(pages))
+	for _, page := range pages {
+		fonts := doc.pageFonts(page, 0)
+		var builder strings.Builder
+		for _, content := range doc.pageContents(page) {
+			builder.WriteString(extractContentText(content, fonts))
+			builder.WriteByte('\n')
+		}
+		texts = append(texts, builder.String())
+	}
+
+	return strings.Join(texts, "\n"), len(pages), nil
+}
+
+func parsePdf(data []byte, limit *decodeLimit) (*pdfDocument, error) {
+	doc := &pdfDocument{
+		objects: make(map[int][]byte),
+		streams: make(map[int][]byte),
+		cmaps:   make(map[int]*pdfCMap),
+	}
+
+	headers := pdfObjectRegex.FindAllSubmatchIndex(data, -1)
+	for index, header := range headers {
+		objNum, _ := strconv.Atoi(string(data[header[2]:header[3]]))
+		end := len(data)
+		if index+1 < len(headers) {
+			end = headers[index+1][0]
+		}
+
+		body := data[header[1]:end]
+		if endObj := bytes.LastIndex(body, []byte("endobj")); endObj >= 0 {
+			body = body[:endObj]
+		}
+
+		streamLoc := pdfStreamRegex.FindIndex(body)
+		if streamLoc == nil {
+			doc.objects[objNum] = body
+			continue
+		}
+
+		dict := body[:streamLoc[0]]
+		stream, err := decodePdfStream(dict, body[streamLoc[1]:], limit)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.objects[objNum] = dict
+		doc.streams[objNum] = stream
+	}
+
+	for objNum, dict := range doc.objects {
+		if bytes.Contains(dict, []byte("/ObjStm")) && doc.streams[objNum] != nil {
+			doc.unpackObjectStream(dict, doc.streams[objNum])
+		}
+	}
+
+	return doc, nil
+}
+
+func decodePdfStream(dict, data []byte, limit *decodeLimit) ([]byte, error) {
+	if matches := pdfLengthRegex.FindSubmatch(dict); matches != nil && len(matches[2]) == 0 {
+		length, _ := strconv.Atoi(string(matches[1]))
+		if length <= len(data) {
+			data = data[:length]
+		}
+	} else if end := bytes.LastIndex(data, []byte("endstream")); end >= 0 {
+		data = data[:end]
+	}
+
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return data, nil
+	}
+
+	if !pdfFlateOnlyRegex.Match(dict) {
+		return nil, nil
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil
+	}
+	defer func() { _ = reader.Close() }()
+
+	// Truncated streams are common, so keep all decoded data.
+	decoded, err := io.ReadAll(limit.wrap(reader))
+	if errors.Is(err, ErrDecodedTooLarge) {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// unpackObjectStream registers objects compressed into object stream.
+func (d *pdfDocument) unpackObjectStream(dict, data []byte) {
+	count := pdfDictInt(dict, "/N")
+	first := pdfDictInt(dict, "/First")
+	if count <= 0 || first <= 0 || first > len(data) {
+		return
+	}
+
+	fields := strings.Fields(string(data[:first]))
+	for index := 0; index+1 < len(fields) && index/2 < count; index += 2 {
+		objNum, _ := strconv.Atoi(fields[index])
+		offset, _ := strconv.Atoi(fields[index+1])
+
+		end := len(data)
+		if index+3 < len(fields) {
+			nextOffset, _ := strconv.Atoi(fields[index+3])
+			end = first + nextOffset
+		}
+
+		start := first + offset
+		if start < end && end <= len(data) {
+			if _, ok := d.objects[objNum]; !ok {
+				d.objects[objNum] = data[start:end]
+			}
+		}
+	}
+}
+
+// pages returns page objects in document order walking pages tree from root.
+func (d *pdfDocument) pages(data []byte) [][]byte {
+	pages := make([][]byte, 0)
+	if roots := pdfRootRegex.FindAllSubmatch(data, -1); len(roots) > 0 {
+		rootNum, _ := strconv.Atoi(string(roots[len(roots)-1][1]))
+		if pagesRef, ok := pdfDictRef(d.objects[rootNum], "/Pages"); ok {
+			d.walkPages(pagesRef, make(map[int]bool), &pages)
+		}
+	}
+
+	if len(pages) > 0 {
+		return pages
+	}
+
+	objNums := make([]int, 0)
+	for objNum, dict := range d.objects {
+		if pdfPageTypeRegex.Match(dict) {
+			objNums = append(objNums, objNum)
+		}
+	}
+
+	sort.Ints(objNums)
+	for _, objNum := range objNums {
+		pages = append(pages, d.objects[objNum])
+	}
+
+	return pages
+}
+
+func (d *pdfDocument) walkPages(objNum int, visited map[int]bool, pages *[][]byte) {
+	if visited[objNum] {
+		return
+	}
+	visited[objNum] = true
+
+	dict, ok := d.objects[objNum]
+	if !ok {
+		return
+	}
+
+	kids, ok := pdfDictValue(dict, "/Kids")
+	if !ok {
+		if pdfPageTypeRegex.Match(dict) {
+			*pages = append(*pages, dict)
+		}
+		return
+	}
+
+	for _, kid := range d.resolveRefs(kids) {
+		d.walkPages(kid, visited, pages)
+	}
+}
+
+func (d *pdfDocument) pageContents(page []byte) [][]byte {
+	value, ok := pdfDictValue(page, "/Contents")
+	if !ok {
+		return nil
+	}
+
+	contents := make([][]byte, 0)
+	for _, objNum := range d.resolveRefs(value) {
+		if stream := d.streams[objNum]; stream != nil {
+			contents = append(contents, stream)
+		}
+	}
+
+	return contents
+}
+
+// pageFonts returns ToUnicode maps of page fonts by resource names
+// looking into parent pages for inherited resources.
+func (d *pdfDocument) pageFonts(page []byte, depth int) map[string]*pdfCMap {
+	fonts := make(map[string]*pdfCMap)
+	resources, ok := d.dictEntry(page, "/Resources")
+	if !ok {
+		if parent, ok := pdfDictRef(page, "/Parent"); ok && depth < 32 {
+			return d.pageFonts(d.objects[parent], depth+1)
+		}
+		return fonts
+	}
+
+	fontDict, ok := d.dictEntry(resources, "/Font")
+	if !ok {
+		return fonts
+	}
+
+	for _, match := range pdfNamedRefRegex.FindAllSubmatch(fontDict, -1) {
+		fontNum, _ := strconv.Atoi(string(match[2]))
+		if cmapNum, ok := pdfDictRef(d.objects[fontNum], "/ToUnicode"); ok {
+			fonts[string(match[1])] = d.cmap(cmapNum)
+		}
+	}
+
+	return fonts
+}
+
+func (d *pdfDocument) cmap(objNum int) *pdfCMap {
+	if cmap, ok := d.cmaps[objNum]; ok {
+		return cmap
+	}
+
+	cmap := parsePdfCMap(d.streams[objNum])
+	d.cmaps[objNum] = cmap
+	return cmap
+}
+
+// dictEntry returns dictionary value by key resolving indirect reference.
+func (d *pdfDocument) dictEntry(dict []byte, key string) ([]byte, bool) {
+	value, ok := pdfDictValue(dict, key)
+	if !ok {
+		return nil, false
+	}
+
+	if bytes.HasPrefix(value, []byte("<<")) {
+		return value, true
+	}
+
+	if matches := pdfRefRegex.FindSubmatch(value); matches != nil {
+		objNum, _ := strconv.Atoi(string(matches[1]))
+		obj, ok := d.objects[objNum]
+		return obj, ok
+	}
+
+	return nil, false
+}
+
+// resolveRefs returns object numbers of a single reference or array
+// of references, also stored as an indirect array object.
+func (d *pdfDocument) resolveRefs(value []byte) []int {
+	refs := make([]int, 0)
+	for _, match := range pdfRefRegex.FindAllSubmatch(value, -1) {
+		objNum, _ := strconv.Atoi(string(match[1]))
+		refs = append(refs, objNum)
+	}
+
+	if len(refs) == 1 && d.streams[refs[0]] == nil {
+		obj := bytes.TrimSpace(d.objects[refs[0]])
+		if bytes.HasPrefix(obj, []byte("[")) {
+			return d.resolveRefs(obj)
+		}
+	}
+
+	return refs
+}
+
+// pdfDictValue returns raw value of key: a balanced dictionary or array,
+// or a reference or a plain token otherwise.
+func pdfDictValue(dict []byte, key string) ([]byte, bool) {
+	index := pdfKeyIndex(dict, key)
+	if index < 0 {
+		return nil, false
+	}
+
+	rest := bytes.TrimLeft(dict[index+len(key):], " \t\r\n")
+	switch {
+	case bytes.HasPrefix(rest, []byte("<<")):
+		return balancedSlice(rest, "<<", ">>"), true
+	case bytes.HasPrefix(rest, []byte("[")):
+		return balancedSlice(rest, "[", "]"), true
+	}
+
+	if matches := pdfRefRegex.FindIndex(rest); matches != nil && matches[0] == 0 {
+		return rest[:matches[1]], true
+	}
+
+	end := bytes.IndexAny(rest, " \t\r\n/>[")
+	if end < 0 {
+		end = len(rest)
+	}
+
+	return rest[:end], true
+}
+
+// pdfKeyIndex returns index of dictionary key, so /Page does not match /Pages.
+func pdfKeyIndex(dict []byte, key string) int {
+	for offset := 0; offset < len(dict); {
+		index := bytes.Index(dict[offset:], []byte(key))
+		if index < 0 {
+			return -1
+		}
+
+		end := offset + index + len(key)
+		if end >= len(dict) || isPdfWhitespace(dict[end]) || isPdfDelimiter(dict[end]) {
+			return offset + index
+		}
+		offset = end
+	}
+
+	return -1
+}
+
+func pdfDictRef(dict []byte, key string) (int, bool) {
+	value, ok := pdfDictValue(dict, key)
+	if !ok {
+		return 0, false
+	}
+
+	matches := pdfRefRegex.FindSubmatch(value)
+	if matches == nil {
+		return 0, false
+	}
+
+	objNum, _ := strconv.Atoi(string(matches[1]))
+	return objNum, true
+}
+
+func pdfDictInt(dict []byte, key string) int {
+	value, ok := pdfDictValue(dict, key)
+	if !ok {
+		return 0
+	}
+
+	number, _ := strconv.Atoi(string(value))
+	return number
+}
+
+func balancedSlice(data []byte, open, close string) []byte {
+	depth := 0
+	for index := 0; index < len(data); {
+		switch {
+		case bytes.HasPrefix(data[index:], []byte(open)):
+			depth++
+			index += len(open)
+		case bytes.HasPrefix(data[index:], []byte(close)):
+			depth--
+			index += len(close)
+			if depth == 0 {
+				return data[:index]
+			}
+		default:
+			index++
+		}
+	}
+
+	return data
+}
+
+// pdfCMap maps character codes of font to unicode text.
+type pdfCMap struct {
+	codeLength int
+	mapping    map[uint32]string
+}
+
+func parsePdfCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{codeLength: 1, mapping: make(map[uint32]string)}
+	text := string(data)
+
+	for _, block := range pdfCMapBlocks(text, "begincodespacerange", "endcodespacerange") {
+		if codes := pdfHexRegex.FindAllStringSubmatch(block, 1); len(codes) > 0 {
+			cmap.codeLength = max(len(strings.Join(strings.Fields(codes[0][1]), ""))/2, 1)
+		}
+	}
+
+	for _, block := range pdfCMapBlocks(text, "beginbfchar", "endbfchar") {
+		codes := pdfHexRegex.FindAllStringSubmatch(block, -1)
+		for index := 0; index+1 < len(codes); index += 2 {
+			cmap.mapping[decodeHexCode(codes[index][1])] = decodeUtf16Hex(codes[index+1][1])
+		}
+	}
+
+	for _, block := range pdfCMapBlocks(text, "beginbfrange", "endbfrange") {
+		for _, line := range strings.Split(block, "\n") {
+			codes := pdfHexRegex.FindAllStringSubmatch(line, -1)
+			if len(codes) < 3 {
+				continue
+			}
+
+			low, high := decodeHexCode(codes[0][1]), decodeHexCode(codes[1][1])
+			if high < low || high-low > 0xFFFF {
+				continue
+			}
+
+			if strings.Contains(line, "[") {
+				for index, code := range codes[2:] {
+					cmap.mapping[low+uint32(index)] = decodeUtf16Hex(code[1])
+				}
+				continue
+			}
+
+			runes := []rune(decodeUtf16Hex(codes[2][1]))
+			if len(runes) == 0 {
+				continue
+			}
+
+			for code := low; code <= high; code++ {
+				shifted := append([]rune{}, runes...)
+				shifted[len(shifted)-1] += rune(code - low)
+				cmap.mapping[code] = string(shifted)
+			}
+		}
+	}
+
+	return cmap
+}
+
+func pdfCMapBlocks(text, begin, end string) []string {
+	blocks := make([]string, 0)
+	for {
+		start := strings.Index(text, begin)
+		if start < 0 {
+			return blocks
+		}
+
+		text = text[start+len(begin):]
+		stop := strings.Index(text, end)
+		if stop < 0 {
+			return blocks
+		}
+
+		blocks = append(blocks, text[:stop])
+		text = text[stop+len(end):]
+	}
+}
+
+func (c *pdfCMap) decode(data []byte) string {
+	var builder strings.Builder
+	for index := 0; index+c.codeLength <= len(data); index += c.codeLength {
+		code := uint32(0)
+		for _, b := range data[index : index+c.codeLength] {
+			code = code<<8 | uint32(b)
+		}
+
+		if text, ok := c.mapping[code]; ok {
+			builder.WriteString(text)
+		}
+	}
+
+	return builder.String()
+}
+
+func decodeHexCode(value string) uint32 {
+	code, _ := strconv.ParseUint(strings.Join(strings.Fields(value), ""), 16, 32)
+	return uint32(code)
+}
+
+func decodeUtf16Hex(value string) string {
+	data, err := hex.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return ""
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for index := 0; index+1 < len(data); index += 2 {
+		units = append(units, uint16(data[index])<<8|uint16(data[index+1]))
+	}
+
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,294 @@
+package native
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// pdfSpaceAdjustment is a minimal TJ offset treated as space between words.
+const pdfSpaceAdjustment = 200
+
+type pdfTokenKind int
+
+const (
+	pdfTokenString pdfTokenKind = iota
+	pdfTokenNumber
+	pdfTokenName
+	pdfTokenArray
+	pdfTokenOther
+)
+
+type pdfToken struct {
+	kind   pdfTokenKind
+	data   []byte
+	number float64
+	array  []*pdfToken
+}
+
+// extractContentText interprets text operators of page content stream.
+func extractContentText(content []byte, fonts map[string]*pdfCMap) string {
+	var builder strings.Builder
+	var font *pdfCMap
+
+	lastY, hasY := 0.0, false
+	operands := make([]*pdfToken, 0)
+	arrays := make([]int, 0)
+
+	newLine := func() {
+		if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n") {
+			builder.WriteByte('\n')
+		}
+	}
+
+	var showText func(token *pdfToken)
+	showText = func(token *pdfToken) {
+		switch token.kind {
+		case pdfTokenString:
+			builder.WriteString(decodePdfString(token.data, font))
+		case pdfTokenNumber:
+			if -token.number > pdfSpaceAdjustment {
+				builder.WriteByte(' ')
+			}
+		case pdfTokenArray:
+			for _, item := range token.array {
+				if item.kind != pdfTokenArray {
+					showText(item)
+				}
+			}
+		}
+	}
+
+	lastOperand := func() *pdfToken {
+		if len(operands) == 0 {
+			return &pdfToken{kind: pdfTokenOther}
+		}
+		return operands[len(operands)-1]
+	}
+
+	operand := func(index int) float64 {
+		if index < 0 || index >= len(operands) {
+			return 0
+		}
+		return operands[index].number
+	}
+
+	for pos := 0; pos < len(content); {
+		char := content[pos]
+		switch {
+		case isPdfWhitespace(char):
+			pos++
+
+		case char == '%':
+			for pos < len(content) && content[pos] != '\n' && content[pos] != '\r' {
+				pos++
+			}
+
+		case char == '(':
+			data, next := readPdfLiteral(content, pos)
+			operands = append(operands, &pdfToken{kind: pdfTokenString, data: data})
+			pos = next
+
+		case char == '<' && pos+1 < len(content) && content[pos+1] == '<':
+			pos += len(balancedSlice(content[pos:], "<<", ">>"))
+
+		case char == '<':
+			end := bytes.IndexByte(content[pos:], '>')
+			if end < 0 {
+				pos = len(content)
+				continue
+			}
+
+			hexData := strings.Join(strings.Fields(string(content[pos+1:pos+end])), "")
+			if len(hexData)%2 == 1 {
+				hexData += "0"
+			}
+
+			data, _ := hex.DecodeString(hexData)
+			operands = append(operands, &pdfToken{kind: pdfTokenString, data: data})
+			pos += end + 1
+
+		case char == '[':
+			arrays = append(arrays, len(operands))
+			pos++
+
+		case char == ']':
+			pos++
+			if len(arrays) == 0 {
+				continue
+			}
+
+			start := arrays[len(arrays)-1]
+			arrays = arrays[:len(arrays)-1]
+			array := append([]*pdfToken{}, operands[start:]...)
+			operands = append(operands[:start], &pdfToken{kind: pdfTokenArray, array: array})
+
+		case char == '/':
+			end := pos + 1
+			for end < len(content) && !isPdfWhitespace(content[end]) && !isPdfDelimiter(content[end]) {
+				end++
+			}
+			operands = append(operands, &pdfToken{kind: pdfTokenName, data: content[pos+1 : end]})
+			pos = end
+
+		default:
+			end := pos + 1
+			for end < len(content) && !isPdfWhitespace(content[end]) && !isPdfDelimiter(content[end]) {
+				end++
+			}
+
+			word := string(content[pos:end])
+			pos = end
+			if number, err := strconv.ParseFloat(word, 64); err == nil {
+				operands = append(operands, &pdfToken{kind: pdfTokenNumber, number: number})
+				continue
+			}
+
+			if len(arrays) > 0 {
+				operands = append(operands, &pdfToken{kind: pdfTokenOther})
+				continue
+			}
+
+			switch word {
+			case "Tf":
+				font = nil
+				if len(operands) >= 2 && operands[len(operands)-2].kind == pdfTokenName {
+					font = fonts[string(operands[len(operands)-2].data)]
+				}
+			case "Tj", "TJ":
+				showText(lastOperand())
+			case "'", "\"":
+				newLine()
+				showText(lastOperand())
+			case "T*":
+				newLine()
+			case "Td", "TD":
+				if operand(len(operands)-1) != 0 {
+					newLine()
+				} else if operand(len(operands)-2) > 0 {
+					builder.WriteByte(' ')
+				}
+			case "Tm":
+				y := operand(len(operands) - 1)
+				if hasY && y != lastY {
+					newLine()
+				}
+				lastY, hasY = y, true
+			case "ET":
+				builder.WriteByte(' ')
+			case "BI":
+				pos = skipPdfInlineImage(content, pos)
+			}
+
+			operands = operands[:0]
+		}
+	}
+
+	return builder.String()
+}
+
+// decodePdfString decodes shown string by font ToUnicode map or as
+// single byte string otherwise.
+func decodePdfString(data []byte, font *pdfCMap) string {
+	if font != nil && len(font.mapping) > 0 {
+		return font.decode(data)
+	}
+
+	if bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		return decodeUtf16Hex(hex.EncodeToString(data[2:]))
+	}
+
+	runes := make([]rune, 0, len(data))
+	for _, b := range data {
+		if b >= 0x20 || b == '\t' {
+			runes = append(runes, rune(b))
+		}
+	}
+
+	return string(runes)
+}
+
+// readPdfLiteral reads literal string in parentheses with nesting and escapes.
+func readPdfLiteral(content []byte, pos int) ([]byte, int) {
+	data := make([]byte, 0)
+	depth := 0
+	for pos < len(content) {
+		char := content[pos]
+		switch char {
+		case '(':
+			depth++
+			if depth > 1 {
+				data = append(data, char)
+			}
+			pos++
+		case ')':
+			depth--
+			pos++
+			if depth == 0 {
+				return data, pos
+			}
+			data = append(data, char)
+		case '\\':
+			pos++
+			if pos >= len(content) {
+				return data, pos
+			}
+
+			escaped := content[pos]
+			switch escaped {
+			case 'n':
+				data = append(data, '\n')
+			case 'r':
+				data = append(data, '\r')
+			case 't':
+				data = append(data, '\t')
+			case 'b':
+				data = append(data, '\b')
+			case 'f':
+				data = append(data, '\f')
+			case '\r', '\n':
+				if escaped == '\r' && pos+1 < len(content) && content[pos+1] == '\n' {
+					pos++
+				}
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					end := pos
+					for end < len(content) && end < pos+3 && content[end] >= '0' && content[end] <= '7' {
+						end++
+					}
+					value, _ := strconv.ParseUint(string(content[pos:end]), 8, 8)
+					data = append(data, byte(value))
+					pos = end - 1
+				} else {
+					data = append(data, escaped)
+				}
+			}
+			pos++
+		default:
+			data = append(data, char)
+			pos++
+		}
+	}
+
+	return data, pos
+}
+
+func skipPdfInlineImage(content []byte, pos int) int {
+	for index := pos; index+2 < len(content); index++ {
+		if content[index] == 'E' && content[index+1] == 'I' &&
+			isPdfWhitespace(content[index-1]) && isPdfWhitespace(content[index+2]) {
+			return index + 2
+		}
+	}
+
+	return len(content)
+}
+
+func isPdfWhitespace(char byte) bool {
+	return char == ' ' || char == '\n' || char == '\r' || char == '\t' || char == '\f' || char == 0
+}
+
+func isPdfDelimiter(char byte) bool {
+	return strings.IndexByte("()<>[]{}/%", char) >= 0
+}
@@ -0,0 +1,250 @@
+package native
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPdf builds pdf document with numbered objects, catalog is expected
+// to be the first object.
+func buildPdf(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	for index, object := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", index+1, object)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func pdfStream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(data []byte) []byte {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+	return buf.Bytes()
+}
+
+func singlePagePdf(content string) []byte {
+	return buildPdf(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStream("/Filter /FlateDecode", flate([]byte(content))),
+	)
+}
+
+func TestExtractPdfFlateStreams(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		text  string
+		pages int
+	}{
+		{
+			name:  "flate content",
+			data:  singlePagePdf("BT /F1 12 Tf 72 700 Td (Hello world) Tj ET"),
+			text:  "Hello world",
+			pages: 1,
+		},
+		{
+			name: "plain content",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStream("", []byte("BT (Plain text) Tj ET")),
+			),
+			text:  "Plain text",
+			pages: 1,
+		},
+		{
+			name: "pages in tree order",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [4 0 R 3 0 R] /Count 2 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+				pdfStream("/Filter /FlateDecode", flate([]byte("BT (Second) Tj ET"))),
+				pdfStream("/Filter [/FlateDecode]", flate([]byte("BT (First) Tj ET"))),
+			),
+			text:  "First\nSecond",
+			pages: 2,
+		},
+		{
+			name: "content array",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 5 0 R] >>",
+				pdfStream("/Filter /FlateDecode", flate([]byte("BT (One) Tj ET"))),
+				pdfStream("/Filter /FlateDecode", flate([]byte("BT (Two) Tj ET"))),
+			),
+			text:  "One\nTwo",
+			pages: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			extracted, err := ExtractText(".pdf", test.data, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if extracted.Text != test.text {
+				t.Errorf("text = %q, want %q", extracted.Text, test.text)
+			}
+
+			if extracted.PagesCount != test.pages {
+				t.Errorf("pages = %d, want %d", extracted.PagesCount, test.pages)
+			}
+		})
+	}
+}
+
+func TestExtractContentTextOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		text    string
+	}{
+		{"show string", "BT (Hello) Tj ET", "Hello"},
+		{"show array with word spacing", "BT [(Hel) 10 (lo) -250 (world)] TJ ET", "Hello world"},
+		{"next line operator", "BT (First) Tj T* (Second) Tj ET", "First\nSecond"},
+		{"move to next line and show", "BT (First) Tj (Second) ' ET", "First\nSecond"},
+		{"move with spacing and show", "BT (First) Tj 1 2 (Second) \" ET", "First\nSecond"},
+		{"line offset", "BT (First) Tj 0 -14 Td (Second) Tj ET", "First\nSecond"},
+		{"horizontal offset", "BT (First) Tj 50 0 Td (Second) Tj ET", "First Second"},
+		{"text matrix lines", "BT 1 0 0 1 72 700 Tm (First) Tj 1 0 0 1 72 680 Tm (Second) Tj ET", "First\nSecond"},
+		{"hex string", "BT <48656C6C6F> Tj ET", "Hello"},
+		{"odd hex string", "BT <4869204> Tj ET", "Hi @"},
+		{"escaped literal", `BT (a\(b\)c \101\102 \\) Tj ET`, `a(b)c AB \`},
+		{"nested parentheses", "BT (a (nested) string) Tj ET", "a (nested) string"},
+		{"utf16 string", "BT <FEFF041F04400438> Tj ET", "При"},
+		{"comment", "% comment (skipped) Tj\nBT (Text) Tj ET", "Text"},
+		{"inline image", "BI /W 1 /H 1 ID \x00(x)\x00 EI BT (Text) Tj ET", "Text"},
+		{"dictionary operand", "/P << /MCID 0 >> BDC BT (Text) Tj ET EMC", "Text"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text := normalizeText(extractContentText([]byte(test.content), nil))
+			if text != test.text {
+				t.Errorf("text = %q, want %q", text, test.text)
+			}
+		})
+	}
+}
+
+func TestExtractContentTextToUnicode(t *testing.T) {
+	cmap := parsePdfCMap([]byte(strings.Join([]string{
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange",
+		"2 beginbfchar <0001> <0041> <0002> <0042> endbfchar",
+		"1 beginbfrange <0010> <0012> <0430> endbfrange",
+	}, "\n")))
+
+	fonts := map[string]*pdfCMap{"F1": cmap}
+	text := extractContentText([]byte("BT /F1 12 Tf <000100020010001100120003> Tj ET"), fonts)
+	if got := normalizeText(text); got != "ABабв" {
+		t.Errorf("text = %q, want %q", got, "ABабв")
+	}
+}
+
+func TestExtractPdfMalformed(t *testing.T) {
+	truncated := flate([]byte("BT (Truncated text) Tj ET"))
+	truncated = truncated[:len(truncated)-6]
+
+	tests := []struct {
+		name    string
+		data    []byte
+		text    string
+		wantErr bool
+	}{
+		{name: "empty data", data: nil, wantErr: true},
+		{name: "no header", data: []byte("not a pdf document"), wantErr: true},
+		{name: "no pages", data: buildPdf("<< /Type /Catalog >>"), wantErr: true},
+		{name: "garbage after header", data: []byte("%PDF-1.4\n\x00\xff 1 0 obj << /Type /Page"), text: ""},
+		{
+			name: "truncated flate stream",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStream("/Filter /FlateDecode", truncated),
+			),
+			text: "Truncated text",
+		},
+		{
+			name: "broken flate stream",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStream("/Filter /FlateDecode", []byte("not compressed")),
+			),
+			text: "",
+		},
+		{
+			name: "unsupported filter",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStream("/Filter /DCTDecode", []byte("BT (Image) Tj ET")),
+			),
+			text: "",
+		},
+		{
+			name: "cyclic pages tree",
+			data: buildPdf(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [2 0 R 3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStream("", []byte("BT (Cycle) Tj ET")),
+			),
+			text: "Cycle",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			extracted, err := ExtractText(".pdf", test.data, 0)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got text %q", extracted.Text)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if extracted.Text != test.text {
+				t.Errorf("text = %q, want %q", extracted.Text, test.text)
+			}
+		})
+	}
+}
+
+func TestExtractPdfDecodeLimit(t *testing.T) {
+	content := "BT (" + strings.Repeat("a", 64<<10) + ") Tj ET"
+	data := singlePagePdf(content)
+
+	if _, err := ExtractText(".pdf", data, 1<<10); !errors.Is(err, ErrDecodedTooLarge) {
+		t.Fatalf("error = %v, want %v", err, ErrDecodedTooLarge)
+	}
+
+	if _, err := ExtractText(".pdf", data, int64(len(content))); err != nil {
+		t.Fatalf("unexpected error for stream of limit size: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/watcher"
+)
+
+// Service splits multipage documents into pages and recognizes them
+// concurrently by wrapped recognizer. Other documents are passed as is.
+type Service struct {
+	config     *ocr.PagesConfig
+	recognizer ocr.Recognizer
+}
+
+func New(config *ocr.Config, recognizer *ocr.Service) *ocr.Service {
+	servClient := &Service{
+		config:     &config.Pages,
+		recognizer: recognizer.Ocr,
+	}
+
+	return &ocr.Service{
+		Ocr: servClient,
+	}
+}
+
+func (s *Service) RecognizeFile(ctx context.Context, document *watcher.Document, data []byte) error {
+	extension := strings.ToLower(document.DocumentExtension)
+	if !s.config.Enabled || !isMultipage(extension) {
+		return s.recognizer.RecognizeFile(ctx, document, data)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "doc-watcher-pages-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	pagePaths, err := s.splitPages(ctx, tmpDir, extension, data)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("recognizing %d pages of file %s", len(pagePaths), document.DocumentName)
+
+	pageDocs, err := s.recognizePages(ctx, document, pagePaths)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return mergePages(document, pageDocs)
+}
+
+// recognizePages recognizes pages concurrently bounded by config. Blank
+// pages are kept empty, other page failures fail the whole document.
+func (s *Service) recognizePages(ctx context.Context, document *watcher.Document, pagePaths []string) ([]*watcher.Document, error) {
+	pageDocs := make([]*watcher.Document, len(pagePaths))
+	pageErrs := make([]error, len(pagePaths))
+	limit := make(chan struct{}, max(s.config.Concurrency, 1))
+
+	wg := &sync.WaitGroup{}
+	for index, pagePath := range pagePaths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+			case <-ctx.Done():
+				return
+			}
+
+			pageData, err := os.ReadFile(pagePath)
+			if err != nil {
+				pageErrs[index] = fmt.Errorf("failed to read page %d: %w", index+1, err)
+				return
+			}
+
+			pageExt := filepath.Ext(pagePath)
+			pageDoc := &watcher.Document{
+				FolderID:          document.FolderID,
+				DocumentName:      fmt.Sprintf("%s-page-%d%s", document.DocumentName, index+1, pageExt),
+				DocumentPath:      document.DocumentPath,
+				DocumentSize:      int64(len(pageData)),
+				DocumentType:      watcher.ParseDocumentType(pageExt),
+				DocumentExtension: pageExt,
+			}
+
+			err = s.recognizer.RecognizeFile(ctx, pageDoc, pageData)
+			if err != nil && !errors.Is(err, ocr.ErrEmptyContent) {
+				pageErrs[index] = fmt.Errorf("failed to recognize page %d: %w", index+1, err)
+				return
+			}
+
+			pageDocs[index] = pageDoc
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(pageErrs...); err != nil {
+		return nil, fmt.Errorf("failed to recognize pages of %s: %w", document.DocumentName, err)
+	}
+
+	return pageDocs, nil
+}
+
+// mergePages fills document by recognized pages keeping texts per page.
+func mergePages(document *watcher.Document, pageDocs []*watcher.Document) error {
+	texts := make([]string, len(pageDocs))
+	ocrMetadata := watcher.DefaultOcr()
+	ocrMetadata.PagesCount = len(pageDocs)
+
+	recognized := 0
+	qualitySum := int64(0)
+	for index, pageDoc := range pageDocs {
+		texts[index] = pageDoc.Content
+		if len(pageDoc.Content) > 0 {
+			recognized++
+			qualitySum += int64(pageDoc.QualityRecognized)
+		}
+
+		pageMeta := pageDoc.OcrMetadata
+		if pageMeta == nil {
+			continue
+		}
+
+		ocrMetadata.Recognizer = pageMeta.Recognizer
+		ocrMetadata.Artifacts = append(ocrMetadata.Artifacts, pageMeta.Artifacts...)
+		if len(ocrMetadata.DocType) == 0 {
+			ocrMetadata.DocType = pageMeta.DocType
+		}
+	}
+
+	document.SetPages(texts)
+	document.SetOcrMetadata(ocrMetadata)
+	document.SetDocumentClass(ocrMetadata.DocType)
+
+	if recognized == 0 {
+		return ocr.ErrEmptyContent
+	}
+
+	document.SetQuality(int32(qualitySum / int64(recognized)))
+	return nil
+}
+
+// splitPages writes document pages as separated images in page order.
+func (s *Service) splitPages(ctx context.Context, tmpDir, extension string, data []byte) ([]string, error) {
+	inputPath := filepath.Join(tmpDir, "input"+extension)
+	if err := os.WriteFile(inputPath, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write document to temp file: %w", err)
+	}
+
+	if extension == ".pdf" {
+		return RenderPdf(ctx, s.config.PdfCommand, s.config.Dpi, inputPath, tmpDir)
+	}
+
+	return splitTiff(ctx, s.config.TiffCommand, inputPath, tmpDir)
+}
+
+func isMultipage(extension string) bool {
+	switch extension {
+	case ".pdf", ".tif", ".tiff":
+		return true
+	default:
+		return false
+	}
+}
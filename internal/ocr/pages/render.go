@@ -0,0 +1,64 @@
+package pages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenderPdf renders pdf pages to png images by pdftoppm in page order.
+func RenderPdf(ctx context.Context, command string, dpi int, inputPath, tmpDir string) ([]string, error) {
+	outputPrefix := filepath.Join(tmpDir, "page")
+	args := []string{"-r", strconv.Itoa(dpi), "-png", inputPath, outputPrefix}
+	if err := run(ctx, command, args...); err != nil {
+		return nil, fmt.Errorf("failed to render pdf pages: %w", err)
+	}
+
+	return collectPages(outputPrefix + "-*.png")
+}
+
+// splitTiff writes tiff pages as separated images by tiffsplit in page order.
+func splitTiff(ctx context.Context, command, inputPath, tmpDir string) ([]string, error) {
+	outputPrefix := filepath.Join(tmpDir, "page-")
+	if err := run(ctx, command, inputPath, outputPrefix); err != nil {
+		return nil, fmt.Errorf("failed to split tiff pages: %w", err)
+	}
+
+	return collectPages(outputPrefix + "*")
+}
+
+// collectPages returns page images matched by pattern. Both pdftoppm and
+// tiffsplit name pages with the same width, so names sort in page order.
+func collectPages(pattern string) ([]string, error) {
+	pagePaths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pagePaths) == 0 {
+		return nil, fmt.Errorf("document has no split pages")
+	}
+
+	sort.Strings(pagePaths)
+	return pagePaths, nil
+}
+
+func run(ctx context.Context, command string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s exceeded document timeout: %w", command, ctx.Err())
+		}
+		return fmt.Errorf("%s failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package passthrough
+
+import (
+	"context"
+	"log"
+
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/watcher"
+)
+
+const RecognizerName = "passthrough"
+
+// Service stands in for recognizers which are not deployed yet, like audio
+// transcription, so documents are stored with metadata only.
+type Service struct{}
+
+func New() *ocr.Service {
+	return &ocr.Service{
+		Ocr: &Service{},
+	}
+}
+
+func (s *Service) RecognizeFile(_ context.Context, document *watcher.Document, _ []byte) error {
+	log.Printf("storing document %s without recognized content", document.DocumentName)
+
+	ocrMetadata := watcher.DefaultOcr()
+	ocrMetadata.Recognizer = RecognizerName
+
+	document.SetContentData("")
+	document.SetOcrMetadata(ocrMetadata)
+	return nil
+}
@@ -1,13 +1,20 @@
 package ocr
 
 import (
+	"context"
+	"errors"
+
 	"doc-watcher/internal/watcher"
 )
 
+// ErrEmptyContent is returned when document has been recognized without
+// text, so recognizing it again returns the same result.
+var ErrEmptyContent = errors.New("returned empty content data")
+
 type Service struct {
 	Ocr Recognizer
 }
 
 type Recognizer interface {
-	RecognizeFile(document *watcher.Document, data []byte) error
+	RecognizeFile(ctx context.Context, document *watcher.Document, data []byte) error
 }
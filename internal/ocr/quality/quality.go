@@ -0,0 +1,148 @@
+package quality
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxScore is a score of perfectly recognized text.
+const MaxScore = 10000
+
+const (
+	// minWordsCount is a count of words to consider words ratio reliable.
+	minWordsCount = 3
+	// maxWordLength is a length of the longest word-like token.
+	maxWordLength = 30
+
+	printableWeight  = 0.4
+	wordsWeight      = 0.6
+	confidenceWeight = 0.5
+)
+
+const vowels = "aeiouyAEIOUYаеёиоуыэюяАЕЁИОУЫЭЮЯ"
+
+// otherScript groups letters of scripts without special handling.
+var otherScript = &unicode.RangeTable{}
+
+// Estimate scores recognized text from 0 to MaxScore by ratio of printable
+// characters and dictionary-like words. Page confidences from 0 to 100 are
+// mixed into score if recognizer provides them.
+func Estimate(text string, confidences []float64) int32 {
+	printable, total := countPrintable(text)
+	if total == 0 {
+		return 0
+	}
+
+	printableRatio := float64(printable) / float64(total)
+	score := printableRatio
+	if wordlike, words := countWordlike(text); words >= minWordsCount {
+		wordsRatio := float64(wordlike) / float64(words)
+		score = printableRatio*printableWeight + wordsRatio*wordsWeight
+	}
+
+	if len(confidences) > 0 {
+		sum := 0.0
+		for _, confidence := range confidences {
+			sum += min(max(confidence, 0), 100) / 100
+		}
+		score = score*(1-confidenceWeight) + sum/float64(len(confidences))*confidenceWeight
+	}
+
+	return int32(score * MaxScore)
+}
+
+// countPrintable returns count of meaningful and all non-space characters.
+func countPrintable(text string) (int, int) {
+	printable, total := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		total++
+		switch {
+		case r == unicode.ReplacementChar:
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsPunct(r):
+			printable++
+		case unicode.In(r, unicode.Sc, unicode.Sm):
+			printable++
+		}
+	}
+
+	return printable, total
+}
+
+// countWordlike returns count of word-like tokens and tokens having letters.
+func countWordlike(text string) (int, int) {
+	wordlike, words := 0, 0
+	for _, token := range strings.Fields(text) {
+		token = strings.TrimFunc(token, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		if !strings.ContainsFunc(token, unicode.IsLetter) {
+			continue
+		}
+
+		words++
+		if isWordlike(token) {
+			wordlike++
+		}
+	}
+
+	return wordlike, words
+}
+
+// isWordlike checks token has letters of a single script with sane case
+// and vowels, so OCR noise like "l1Ke" or "сlоud" mixed scripts is rejected.
+func isWordlike(token string) bool {
+	runes := []rune(token)
+	if len(runes) > maxWordLength {
+		return false
+	}
+
+	var script *unicode.RangeTable
+	hasVowel, hasLower := false, false
+	for index, r := range runes {
+		if r == '-' || r == '\'' || r == '’' {
+			continue
+		}
+
+		if !unicode.IsLetter(r) {
+			return false
+		}
+
+		runeScript := scriptOf(r)
+		if script == nil {
+			script = runeScript
+		} else if script != runeScript {
+			return false
+		}
+
+		if unicode.IsUpper(r) && hasLower && index > 0 {
+			return false
+		}
+
+		hasLower = hasLower || unicode.IsLower(r)
+		hasVowel = hasVowel || strings.ContainsRune(vowels, r)
+	}
+
+	if script == unicode.Latin || script == unicode.Cyrillic {
+		return hasVowel || len(runes) <= 3
+	}
+
+	return true
+}
+
+func scriptOf(r rune) *unicode.RangeTable {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return unicode.Latin
+	case unicode.Is(unicode.Cyrillic, r):
+		return unicode.Cyrillic
+	case unicode.Is(unicode.Greek, r):
+		return unicode.Greek
+	default:
+		return otherScript
+	}
+}
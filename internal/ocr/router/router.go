@@ -0,0 +1,155 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/ocr/passthrough"
+	"doc-watcher/internal/watcher"
+)
+
+// Service dispatches documents to recognizers by configured routes.
+type Service struct {
+	routes      []*ocr.Route
+	defaultName string
+	threshold   int32
+	recognizers map[string]ocr.Recognizer
+}
+
+func New(config *ocr.Config, recognizers map[string]*ocr.Service) *ocr.Service {
+	servClient := &Service{
+		routes:      config.Routes,
+		defaultName: config.DefaultRecognizer,
+		threshold:   config.QualityThreshold,
+		recognizers: make(map[string]ocr.Recognizer, len(recognizers)),
+	}
+
+	for name, recognizer := range recognizers {
+		servClient.recognizers[name] = recognizer.Ocr
+	}
+
+	if _, ok := servClient.recognizers[config.DefaultRecognizer]; !ok {
+		log.Fatalln("unknown default recognizer: ", config.DefaultRecognizer)
+	}
+
+	for _, route := range config.Routes {
+		if _, ok := servClient.recognizers[route.Recognizer]; !ok {
+			log.Fatalln("unknown recognizer of ocr route: ", route.Recognizer)
+		}
+	}
+
+	return &ocr.Service{
+		Ocr: servClient,
+	}
+}
+
+func (s *Service) RecognizeFile(ctx context.Context, document *watcher.Document, data []byte) error {
+	name := s.route(document, data)
+	recognizer := s.recognizers[name]
+
+	if err := recognizer.RecognizeFile(ctx, document, data); err != nil {
+		return fmt.Errorf("recognizer %s failed: %w", name, err)
+	}
+
+	if document.OcrMetadata == nil {
+		document.SetOcrMetadata(watcher.DefaultOcr())
+	}
+
+	if len(document.OcrMetadata.Recognizer) == 0 {
+		document.OcrMetadata.Recognizer = name
+	}
+
+	// passthrough documents have no recognized content to estimate
+	if document.OcrMetadata.Recognizer == passthrough.RecognizerName {
+		return nil
+	}
+
+	if document.QualityRecognized < s.threshold {
+		log.Printf("document %s recognized with low quality %d", document.DocumentName, document.QualityRecognized)
+		document.OcrMetadata.LowQuality = true
+	}
+
+	return nil
+}
+
+// route returns recognizer name of the first matched route.
+func (s *Service) route(document *watcher.Document, data []byte) string {
+	mimeType := detectMimeType(document.DocumentExtension, data)
+	for _, route := range s.routes {
+		if matchRoute(route, document, mimeType) {
+			return route.Recognizer
+		}
+	}
+
+	return s.defaultName
+}
+
+func matchRoute(route *ocr.Route, document *watcher.Document, mimeType string) bool {
+	if len(route.MimeTypes) > 0 && !matchAny(route.MimeTypes, mimeType, matchMimeType) {
+		return false
+	}
+
+	extension := strings.ToLower(document.DocumentExtension)
+	if len(route.Extensions) > 0 && !matchAny(route.Extensions, extension, matchExtension) {
+		return false
+	}
+
+	if len(route.Buckets) > 0 && !matchAny(route.Buckets, document.FolderID, strings.EqualFold) {
+		return false
+	}
+
+	if route.MinSize > 0 && document.DocumentSize < route.MinSize {
+		return false
+	}
+
+	if route.MaxSize > 0 && document.DocumentSize > route.MaxSize {
+		return false
+	}
+
+	return true
+}
+
+func matchAny(patterns []string, value string, match func(pattern, value string) bool) bool {
+	for _, pattern := range patterns {
+		if match(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchMimeType matches mime type by exact value or wildcard like image/*.
+func matchMimeType(pattern, mimeType string) bool {
+	pattern = strings.ToLower(pattern)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(mimeType, prefix)
+	}
+
+	return pattern == mimeType
+}
+
+func matchExtension(pattern, extension string) bool {
+	return "."+strings.TrimPrefix(strings.ToLower(pattern), ".") == extension
+}
+
+// detectMimeType returns mime type by extension or sniffs it from content
+// when system has no mime type of extension.
+func detectMimeType(extension string, data []byte) string {
+	mimeType := mime.TypeByExtension(strings.ToLower(extension))
+	if len(mimeType) == 0 {
+		mimeType = http.DetectContentType(data)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return strings.ToLower(mimeType)
+	}
+
+	return mediaType
+}
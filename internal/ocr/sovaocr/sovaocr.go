@@ -2,18 +2,26 @@ package sovaocr
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
+	"net/http"
 	"time"
 
 	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/ocr/quality"
 	"doc-watcher/internal/sender"
 	"doc-watcher/internal/watcher"
 )
 
-const RecognitionURL = "/ocr_extract_text"
+const (
+	RecognizerName = "sova"
+	RecognitionURL = "/ocr_extract_text"
+	JobsURL        = "/ocr_jobs"
+)
 
 type Service struct {
 	config *ocr.Config
@@ -29,7 +37,7 @@ func New(config *ocr.Config) *ocr.Service {
 	}
 }
 
-func (s *Service) RecognizeFile(document *watcher.Document, data []byte) error {
+func (s *Service) RecognizeFile(ctx context.Context, document *watcher.Document, data []byte) error {
 	var buf bytes.Buffer
 
 	mpw := multipart.NewWriter(&buf)
@@ -48,24 +56,153 @@ func (s *Service) RecognizeFile(document *watcher.Document, data []byte) error {
 
 	log.Printf("sending file %s to recognize", document.DocumentName)
 
+	var resTest *ocr.DocumentForm
 	mimeType := mpw.FormDataContentType()
-	timeoutReq := s.config.Timeout * time.Second
-	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, RecognitionURL)
+	if s.config.Async.Enabled {
+		resTest, err = s.recognizeAsync(ctx, &buf, mimeType)
+	} else {
+		resTest, err = s.recognizeSync(ctx, &buf, mimeType)
+	}
 
-	respData, err := sender.POST(&buf, targetURL, mimeType, timeoutReq)
 	if err != nil {
-		return fmt.Errorf("failed send request: %w", err)
+		return err
 	}
 
-	var resTest ocr.DocumentForm
-	_ = json.Unmarshal(respData, &resTest)
+	ocrMetadata := resTest.OcrMetadata()
+	ocrMetadata.Recognizer = RecognizerName
+
 	document.SetContentData(resTest.Content)
-	document.SetOcrMetadata(watcher.DefaultOcr())
+	document.SetOcrMetadata(ocrMetadata)
+	document.SetDocumentClass(resTest.DocType)
 
 	if len(resTest.Content) == 0 {
-		return fmt.Errorf("returned empty content data")
+		return ocr.ErrEmptyContent
 	}
 
-	document.SetQuality(10000)
+	document.SetQuality(quality.Estimate(resTest.Content, nil))
 	return nil
 }
+
+func (s *Service) recognizeSync(ctx context.Context, buf *bytes.Buffer, mimeType string) (*ocr.DocumentForm, error) {
+	timeoutReq := s.config.Timeout * time.Second
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, RecognitionURL)
+
+	respData, err := sender.POST(ctx, buf, targetURL, mimeType, timeoutReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed send request: %w", err)
+	}
+
+	resTest := &ocr.DocumentForm{}
+	if err = json.Unmarshal(respData, resTest); err != nil {
+		return nil, fmt.Errorf("failed to decode recognition result: %w", err)
+	}
+
+	return resTest, nil
+}
+
+const (
+	// minPollInterval prevents polling OCR job in busy loop if
+	// poll interval is not configured.
+	minPollInterval = 100 * time.Millisecond
+	// maxPollErrors is a count of consecutive failed polls
+	// after which OCR job is considered as lost.
+	maxPollErrors = 5
+)
+
+// recognizeAsync submits OCR job and polls it with growing interval until
+// completion. Remote job is canceled if context is done before.
+func (s *Service) recognizeAsync(ctx context.Context, buf *bytes.Buffer, mimeType string) (*ocr.DocumentForm, error) {
+	timeoutReq := s.config.Timeout * time.Second
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, JobsURL)
+
+	respData, err := sender.POST(ctx, buf, targetURL, mimeType, timeoutReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit ocr job: %w", err)
+	}
+
+	job := &ocr.JobForm{}
+	if err = json.Unmarshal(respData, job); err != nil || len(job.JobID) == 0 {
+		return nil, fmt.Errorf("failed to decode submitted ocr job: %s", string(respData))
+	}
+
+	log.Printf("submitted ocr job %s", job.JobID)
+
+	jobCtx, cancel := context.WithTimeout(ctx, s.config.Async.JobTimeout*time.Second)
+	defer cancel()
+
+	interval := max(s.config.Async.PollInterval*time.Millisecond, minPollInterval)
+	maxInterval := s.config.Async.MaxPollInterval * time.Millisecond
+	pollErrors := 0
+	for {
+		select {
+		case <-jobCtx.Done():
+			s.cancelJob(job.JobID)
+			return nil, fmt.Errorf("ocr job %s interrupted: %w", job.JobID, jobCtx.Err())
+		case <-time.After(interval):
+		}
+
+		state, err := s.fetchJob(jobCtx, job.JobID)
+		if err != nil && jobCtx.Err() != nil {
+			continue
+		}
+
+		if err != nil {
+			var statusErr *sender.StatusError
+			if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+				return nil, fmt.Errorf("ocr job %s is not found: %w", job.JobID, err)
+			}
+
+			pollErrors++
+			if pollErrors >= maxPollErrors {
+				s.cancelJob(job.JobID)
+				return nil, fmt.Errorf("failed to poll ocr job %s %d times: %w", job.JobID, pollErrors, err)
+			}
+
+			log.Printf("failed to poll ocr job %s: %v", job.JobID, err)
+		} else {
+			pollErrors = 0
+			switch state.Status {
+			case ocr.JobDone:
+				if state.Result == nil {
+					return nil, fmt.Errorf("ocr job %s returned no result", job.JobID)
+				}
+				state.Result.JobID = job.JobID
+				return state.Result, nil
+			case ocr.JobFailed, ocr.JobCanceled:
+				return nil, fmt.Errorf("ocr job %s %s: %s", job.JobID, state.Status, state.Error)
+			}
+		}
+
+		interval = time.Duration(float64(interval) * max(s.config.Async.Multiplier, 1))
+		if maxInterval > 0 && interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func (s *Service) fetchJob(ctx context.Context, jobID string) (*ocr.JobForm, error) {
+	timeoutReq := s.config.Timeout * time.Second
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, JobsURL+"/"+jobID)
+
+	respData, err := sender.GET(ctx, targetURL, timeoutReq)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ocr.JobForm{}
+	if err = json.Unmarshal(respData, state); err != nil {
+		return nil, fmt.Errorf("failed to decode ocr job: %w", err)
+	}
+
+	return state, nil
+}
+
+func (s *Service) cancelJob(jobID string) {
+	log.Printf("canceling ocr job %s", jobID)
+
+	timeoutReq := s.config.Timeout * time.Second
+	targetURL := sender.BuildTargetURL(s.config.EnableSSL, s.config.Address, JobsURL+"/"+jobID)
+	if _, err := sender.DELETE(targetURL, timeoutReq); err != nil {
+		log.Printf("failed to cancel ocr job %s: %v", jobID, err)
+	}
+}
@@ -0,0 +1,205 @@
+package tesseract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/ocr/pages"
+	"doc-watcher/internal/ocr/quality"
+	"doc-watcher/internal/watcher"
+)
+
+const RecognizerName = "tesseract"
+
+// wordLevel is a level of recognized words into tesseract tsv output.
+const wordLevel = "5"
+
+// tsvColumns is a count of columns into tesseract tsv output.
+const tsvColumns = 12
+
+type Service struct {
+	config *ocr.TesseractConfig
+}
+
+func New(config *ocr.Config) *ocr.Service {
+	if _, err := exec.LookPath(config.Tesseract.Command); err != nil {
+		log.Printf("tesseract command %s is not available: %v", config.Tesseract.Command, err)
+	}
+
+	servClient := &Service{
+		config: &config.Tesseract,
+	}
+
+	return &ocr.Service{
+		Ocr: servClient,
+	}
+}
+
+func (s *Service) RecognizeFile(ctx context.Context, document *watcher.Document, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout*time.Second)
+	defer cancel()
+
+	tmpDir, err := os.MkdirTemp("", "doc-watcher-tesseract-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	extension := strings.ToLower(document.DocumentExtension)
+	inputPath := filepath.Join(tmpDir, "input"+extension)
+	if err = os.WriteFile(inputPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write document to temp file: %w", err)
+	}
+
+	images := []string{inputPath}
+	if extension == ".pdf" {
+		images, err = pages.RenderPdf(ctx, s.config.PdfCommand, s.config.Dpi, inputPath, tmpDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("recognizing file %s by tesseract", document.DocumentName)
+
+	recognized := make([]*recognizedPage, 0, len(images))
+	for _, image := range images {
+		imagePages, err := s.recognizeImage(ctx, image)
+		if err != nil {
+			return err
+		}
+
+		recognized = append(recognized, imagePages...)
+	}
+
+	texts := make([]string, 0, len(recognized))
+	confidences := make([]float64, 0, len(recognized))
+	for _, page := range recognized {
+		texts = append(texts, page.text)
+		if page.wordsCount > 0 {
+			confidences = append(confidences, page.confidence)
+		}
+	}
+
+	ocrMetadata := watcher.DefaultOcr()
+	ocrMetadata.PagesCount = max(len(recognized), len(images), 1)
+	ocrMetadata.Recognizer = RecognizerName
+
+	content := strings.TrimSpace(strings.Join(texts, "\n"))
+	document.SetContentData(content)
+	document.SetOcrMetadata(ocrMetadata)
+
+	if len(content) == 0 {
+		return ocr.ErrEmptyContent
+	}
+
+	document.SetQuality(quality.Estimate(content, confidences))
+	return nil
+}
+
+func (s *Service) recognizeImage(ctx context.Context, imagePath string) ([]*recognizedPage, error) {
+	args := []string{imagePath, "stdout"}
+	if len(s.config.Languages) > 0 {
+		args = append(args, "-l", strings.Join(s.config.Languages, "+"))
+	}
+
+	if s.config.Dpi > 0 {
+		args = append(args, "--dpi", strconv.Itoa(s.config.Dpi))
+	}
+
+	output, err := s.execute(ctx, s.config.Command, append(args, "tsv")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recognize image: %w", err)
+	}
+
+	return parseTsv(string(output)), nil
+}
+
+func (s *Service) execute(ctx context.Context, command string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s exceeded document timeout: %w", command, ctx.Err())
+		}
+		return nil, fmt.Errorf("%s failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+type recognizedPage struct {
+	text       string
+	confidence float64
+	wordsCount int
+}
+
+// parseTsv builds text of pages from recognized words of tesseract tsv
+// output and computes average words confidence of each page.
+func parseTsv(output string) []*recognizedPage {
+	pages := make([]*recognizedPage, 0)
+
+	var builder strings.Builder
+	var page *recognizedPage
+	var pageNum, paragraph, line string
+
+	flushPage := func() {
+		if page != nil {
+			page.text = builder.String()
+			if page.wordsCount > 0 {
+				page.confidence /= float64(page.wordsCount)
+			}
+			pages = append(pages, page)
+		}
+		builder.Reset()
+	}
+
+	for _, row := range strings.Split(output, "\n") {
+		columns := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		if len(columns) < tsvColumns || columns[0] != wordLevel {
+			continue
+		}
+
+		word := strings.TrimSpace(columns[11])
+		if len(word) == 0 {
+			continue
+		}
+
+		rowParagraph := columns[2] + "." + columns[3]
+		rowLine := rowParagraph + "." + columns[4]
+		switch {
+		case page == nil || columns[1] != pageNum:
+			flushPage()
+			page = &recognizedPage{}
+		case rowParagraph != paragraph:
+			builder.WriteString("\n\n")
+		case rowLine != line:
+			builder.WriteByte('\n')
+		default:
+			builder.WriteByte(' ')
+		}
+
+		pageNum, paragraph, line = columns[1], rowParagraph, rowLine
+		builder.WriteString(word)
+
+		if confidence, err := strconv.ParseFloat(columns[10], 64); err == nil && confidence >= 0 {
+			page.confidence += confidence
+			page.wordsCount++
+		}
+	}
+
+	flushPage()
+	return pages
+}
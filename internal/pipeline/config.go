@@ -0,0 +1,16 @@
+package pipeline
+
+import "doc-watcher/internal/retry"
+
+type Config struct {
+	Workers         int
+	QueueSize       int
+	DownloadLimit   int
+	OcrLimit        int
+	EmbeddingsLimit int
+	StoreLimit      int
+	DownloadRetry   retry.Policy
+	OcrRetry        retry.Policy
+	EmbeddingsRetry retry.Policy
+	StoreRetry      retry.Policy
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"doc-watcher/internal/storage"
+	"doc-watcher/internal/watcher"
+)
+
+const deadLettersBucket = "pipeline-dead-letters"
+
+// ErrNoJobIDs is returned when dead letters are requested without job ids
+// and without explicit flag to take all of them.
+var ErrNoJobIDs = errors.New("job ids or all flag must be passed")
+
+type deadLetter struct {
+	Failed *watcher.FailedDocument `json:"failed"`
+	Job    *Job                    `json:"job"`
+}
+
+// DeadLetters stores jobs which have been permanently failed
+// to be viewed and requeued later.
+type DeadLetters struct {
+	store *storage.Service
+}
+
+func NewDeadLetters(store *storage.Service) *DeadLetters {
+	return &DeadLetters{store: store}
+}
+
+func (dl *DeadLetters) Store(job *Job, err error) error {
+	failed := &watcher.FailedDocument{
+		JobID:        job.ID,
+		FolderID:     job.Document.FolderID,
+		DocumentPath: job.Document.DocumentPath,
+		DocumentName: job.Document.DocumentName,
+		Attempts:     1,
+		LastError:    err.Error(),
+		FailedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		failed.Stage = stageErr.Stage
+		failed.Attempts = stageErr.Attempts
+	}
+
+	letter := &deadLetter{Failed: failed, Job: job.withoutPayload()}
+	return dl.store.Put(deadLettersBucket, job.ID, letter)
+}
+
+func (dl *DeadLetters) List() ([]*watcher.FailedDocument, error) {
+	letters, err := dl.load()
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make([]*watcher.FailedDocument, len(letters))
+	for index, letter := range letters {
+		failed[index] = letter.Failed
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].FailedAt < failed[j].FailedAt
+	})
+
+	return failed, nil
+}
+
+// Jobs returns jobs of dead letters by passed job ids or jobs
+// of all dead letters if all flag is set.
+func (dl *DeadLetters) Jobs(ids []string, all bool) ([]*Job, error) {
+	letters, err := dl.load()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		filter[id] = true
+	}
+
+	jobs := make([]*Job, 0)
+	for _, letter := range letters {
+		if all || filter[letter.Job.ID] {
+			jobs = append(jobs, letter.Job)
+		}
+	}
+
+	return jobs, nil
+}
+
+func (dl *DeadLetters) Delete(ids ...string) error {
+	return dl.store.Delete(deadLettersBucket, ids...)
+}
+
+func (dl *DeadLetters) load() ([]*deadLetter, error) {
+	letters := make([]*deadLetter, 0)
+	err := dl.store.ForEach(deadLettersBucket, func(key string, data []byte) error {
+		letter := &deadLetter{}
+		if err := json.Unmarshal(data, letter); err != nil {
+			log.Printf("failed to unmarshal dead letter %s: %v", key, err)
+			return nil
+		}
+
+		letters = append(letters, letter)
+		return nil
+	})
+
+	return letters, err
+}
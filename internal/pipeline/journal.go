@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+
+	"doc-watcher/internal/storage"
+)
+
+const jobsBucket = "pipeline-jobs"
+
+// Journal persists enqueued jobs until they have been processed
+// to replay unfinished jobs after service restart.
+type Journal struct {
+	store *storage.Service
+}
+
+func NewJournal(store *storage.Service) *Journal {
+	return &Journal{store: store}
+}
+
+func (j *Journal) Record(job *Job) error {
+	return j.store.Put(jobsBucket, job.ID, job)
+}
+
+func (j *Journal) Complete(job *Job) error {
+	return j.store.Delete(jobsBucket, job.ID)
+}
+
+// Pending returns unfinished jobs ordered by enqueue time.
+func (j *Journal) Pending() ([]*Job, error) {
+	jobs := make([]*Job, 0)
+	err := j.store.ForEach(jobsBucket, func(key string, data []byte) error {
+		job := &Job{}
+		if err := json.Unmarshal(data, job); err != nil {
+			log.Printf("failed to unmarshal journal job %s: %v", key, err)
+			return nil
+		}
+
+		jobs = append(jobs, job)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].CreatedAt < jobs[k].CreatedAt
+	})
+
+	return jobs, nil
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"doc-watcher/internal/watcher"
+	"github.com/google/uuid"
+)
+
+type Action string
+
+const (
+	ActionStore  Action = "store"
+	ActionRemove Action = "remove"
+	// ActionReembed processes indexed file again to replace its vectors.
+	ActionReembed Action = "reembed"
+)
+
+// Job is a unit of pipeline work built by watcher from caught event.
+type Job struct {
+	ID        string            `json:"id"`
+	Action    Action            `json:"action"`
+	FilePath  string            `json:"file_path"`
+	ETag      string            `json:"etag"`
+	Version   string            `json:"version"`
+	CreatedAt int64             `json:"created_at"`
+	Document  *watcher.Document `json:"document"`
+
+	// TargetFolder is a folder to store reembedded document instead of its own.
+	TargetFolder string `json:"target_folder,omitempty"`
+
+	// OnDone is called with processing result when job has been finished.
+	OnDone func(err error) `json:"-"`
+}
+
+// Loader loads file data of job from watched storage.
+type Loader interface {
+	LoadFile(ctx context.Context, job *Job) ([]byte, error)
+}
+
+func NewJob(action Action, filePath, etag string, doc *watcher.Document) *Job {
+	return &Job{
+		ID:        uuid.New().String(),
+		Action:    action,
+		FilePath:  filePath,
+		ETag:      etag,
+		CreatedAt: time.Now().UnixNano(),
+		Document:  doc,
+	}
+}
+
+// StoredDocument returns document to store, it is copied with target
+// folder when reembedded document is stored into another index.
+func (j *Job) StoredDocument() *watcher.Document {
+	if len(j.TargetFolder) == 0 {
+		return j.Document
+	}
+
+	document := *j.Document
+	document.SetFolderID(j.TargetFolder)
+	return &document
+}
+
+// withoutPayload returns copy of job without recognized content and vectors
+// of its document, so job is kept as it has been enqueued.
+func (j *Job) withoutPayload() *Job {
+	document := *j.Document
+	document.SetContentData("")
+	document.SetOcrMetadata(nil)
+	document.SetEmbeddings(nil)
+	document.Pages = nil
+
+	job := *j
+	job.Document = &document
+	return &job
+}
+
+func (j *Job) done(err error) {
+	if j.OnDone != nil {
+		j.OnDone(err)
+	}
+}
@@ -0,0 +1,374 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/events"
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/retry"
+	"doc-watcher/internal/searcher"
+	"doc-watcher/internal/status"
+	"doc-watcher/internal/storage"
+	"doc-watcher/internal/watcher"
+)
+
+// Pipeline processes jobs caught by watchers with bounded count of
+// workers and limits concurrent requests to each of external services.
+type Pipeline struct {
+	config *Config
+	loader Loader
+	queue  chan *Job
+
+	activeJobs atomic.Int64
+	running    sync.Map
+	limits     map[watcher.Stage]chan struct{}
+	inFlight   map[watcher.Stage]*atomic.Int64
+	retries    map[watcher.Stage]*retry.Policy
+
+	statusServ *status.Service
+	events     *events.Broker
+	index      *watcher.ObjectsIndex
+	journal    *Journal
+	unfinished []*Job
+	failed     *DeadLetters
+	ocrServ    *ocr.Service
+	searchServ *searcher.Service
+	tokenServ  *embeddings.Service
+}
+
+type runningJob struct {
+	job    *Job
+	cancel context.CancelFunc
+}
+
+func New(
+	config *Config,
+	loader Loader,
+	statusServ *status.Service,
+	eventsBroker *events.Broker,
+	ocrServ *ocr.Service,
+	searchServ *searcher.Service,
+	tokenServ *embeddings.Service,
+	store *storage.Service,
+) *Pipeline {
+	stageLimits := map[watcher.Stage]int{
+		watcher.StageDownloading: config.DownloadLimit,
+		watcher.StageOcr:         config.OcrLimit,
+		watcher.StageEmbedding:   config.EmbeddingsLimit,
+		watcher.StageStoring:     config.StoreLimit,
+	}
+
+	limits := make(map[watcher.Stage]chan struct{})
+	inFlight := make(map[watcher.Stage]*atomic.Int64)
+	for stage, limit := range stageLimits {
+		inFlight[stage] = &atomic.Int64{}
+		if limit > 0 {
+			limits[stage] = make(chan struct{}, limit)
+		}
+	}
+
+	retries := map[watcher.Stage]*retry.Policy{
+		watcher.StageDownloading: &config.DownloadRetry,
+		watcher.StageOcr:         &config.OcrRetry,
+		watcher.StageEmbedding:   &config.EmbeddingsRetry,
+		watcher.StageStoring:     &config.StoreRetry,
+	}
+
+	journal := NewJournal(store)
+
+	// Unfinished jobs are loaded before watchers start, so jobs
+	// enqueued after launch are not replayed twice.
+	unfinished, err := journal.Pending()
+	if err != nil {
+		log.Printf("failed to load unfinished jobs: %v", err)
+	}
+
+	return &Pipeline{
+		config: config,
+		loader: loader,
+		queue:  make(chan *Job, max(config.QueueSize, 1)),
+
+		limits:   limits,
+		inFlight: inFlight,
+		retries:  retries,
+
+		statusServ: statusServ,
+		events:     eventsBroker,
+		index:      watcher.NewObjectsIndex(store),
+		journal:    journal,
+		unfinished: unfinished,
+		failed:     NewDeadLetters(store),
+		ocrServ:    ocrServ,
+		searchServ: searchServ,
+		tokenServ:  tokenServ,
+	}
+}
+
+// Run launches pipeline workers, replays jobs unfinished before restart
+// and blocks until passed context is done.
+func (p *Pipeline) Run(ctx context.Context) {
+	go p.replayJournal(ctx)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < max(p.config.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.launchWorker(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	// Jobs left into queue stay into journal to be replayed, their
+	// callbacks are notified that jobs have been interrupted.
+	for {
+		select {
+		case job := <-p.queue:
+			job.done(ctx.Err())
+		default:
+			return
+		}
+	}
+}
+
+// Enqueue records job to journal and pushes it to pipeline queue. It blocks
+// while queue is full to apply backpressure to event listeners.
+func (p *Pipeline) Enqueue(ctx context.Context, job *Job) error {
+	if err := p.journal.Record(job); err != nil {
+		return fmt.Errorf("failed to record job to journal: %w", err)
+	}
+
+	return p.push(ctx, job)
+}
+
+func (p *Pipeline) push(ctx context.Context, job *Job) error {
+	if job.Action == ActionStore {
+		job.Document.SetQuality(-1)
+		p.updateStatus(job, watcher.StageQueued, nil)
+	}
+
+	select {
+	case p.queue <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cancel interrupts running jobs matched by passed function, so
+// recognizers may cancel remote jobs of these documents.
+func (p *Pipeline) Cancel(match func(job *Job) bool) {
+	p.running.Range(func(_, value any) bool {
+		running := value.(*runningJob)
+		if match(running.job) {
+			running.cancel()
+		}
+		return true
+	})
+}
+
+// EmbeddingsModel returns model computing vectors of processed documents,
+// documents stored without vectors have no model.
+func (p *Pipeline) EmbeddingsModel(ctx context.Context) (string, error) {
+	if !p.searchServ.StoresVectors() {
+		return "", nil
+	}
+
+	return p.tokenServ.Tokenizer.Model(ctx)
+}
+
+func (p *Pipeline) FailedDocuments() ([]*watcher.FailedDocument, error) {
+	return p.failed.List()
+}
+
+// RequeueFailed moves dead letters by passed job ids or all dead letters
+// back to queue. Dead letter is deleted only after its job has been
+// enqueued, so jobs are not lost if enqueueing fails.
+func (p *Pipeline) RequeueFailed(ctx context.Context, jobIDs []string, all bool) error {
+	if len(jobIDs) == 0 && !all {
+		return ErrNoJobIDs
+	}
+
+	jobs, err := p.failed.Jobs(jobIDs, all)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err = p.Enqueue(ctx, job); err != nil {
+			return err
+		}
+
+		if err = p.failed.Delete(job.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CleanFailed deletes dead letters by passed job ids or all
+// dead letters if all flag is set.
+func (p *Pipeline) CleanFailed(jobIDs []string, all bool) error {
+	if len(jobIDs) == 0 && !all {
+		return ErrNoJobIDs
+	}
+
+	jobs, err := p.failed.Jobs(jobIDs, all)
+	if err != nil {
+		return err
+	}
+
+	cleanIDs := make([]string, len(jobs))
+	for index, job := range jobs {
+		cleanIDs[index] = job.ID
+	}
+
+	return p.failed.Delete(cleanIDs...)
+}
+
+func (p *Pipeline) Stats() *watcher.QueueStats {
+	stages := make(map[watcher.Stage]int64, len(p.inFlight))
+	for stage, counter := range p.inFlight {
+		stages[stage] = counter.Load()
+	}
+
+	return &watcher.QueueStats{
+		Workers:        max(p.config.Workers, 1),
+		QueueSize:      cap(p.queue),
+		QueueDepth:     len(p.queue),
+		ActiveJobs:     p.activeJobs.Load(),
+		StagesInFlight: stages,
+	}
+}
+
+func (p *Pipeline) launchWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-p.queue:
+			jobCtx, cancel := context.WithCancel(ctx)
+			p.running.Store(job.ID, &runningJob{job: job, cancel: cancel})
+
+			p.activeJobs.Add(1)
+			err := p.processJob(jobCtx, job)
+			p.activeJobs.Add(-1)
+
+			p.running.Delete(job.ID)
+			canceled := jobCtx.Err() != nil
+			cancel()
+
+			if ctx.Err() != nil {
+				// Interrupted job stays into journal to be replayed.
+				job.done(ctx.Err())
+				return
+			}
+
+			if canceled {
+				log.Printf("processing of file %s has been canceled", job.FilePath)
+				if delErr := p.statusServ.Store.Delete(job.Document, job.Version); delErr != nil {
+					log.Printf("failed to delete status of file %s: %v", job.FilePath, delErr)
+				}
+			} else if err != nil {
+				log.Printf("failed to process file %s: %v", job.FilePath, err)
+				if storeErr := p.failed.Store(job, err); storeErr != nil {
+					log.Printf("failed to store dead letter %s: %v", job.ID, storeErr)
+				}
+			}
+
+			if completeErr := p.journal.Complete(job); completeErr != nil {
+				log.Printf("failed to complete journal job %s: %v", job.ID, completeErr)
+			}
+			job.done(err)
+		}
+	}
+}
+
+func (p *Pipeline) replayJournal(ctx context.Context) {
+	jobs := p.unfinished
+	p.unfinished = nil
+
+	if len(jobs) > 0 {
+		log.Printf("replaying %d unfinished jobs", len(jobs))
+	}
+
+	for _, job := range jobs {
+		if err := p.push(ctx, job); err != nil {
+			return
+		}
+	}
+}
+
+// runStage executes stage function and retries it by stage retry policy.
+func (p *Pipeline) runStage(ctx context.Context, job *Job, stage watcher.Stage, fn func() error) error {
+	p.updateStatus(job, stage, nil)
+
+	policy := p.retries[stage]
+	for attempt := 1; ; attempt++ {
+		err := p.runStageOnce(ctx, stage, fn)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= policy.MaxAttempts || IsPermanent(err) || !policy.IsRetryable(err) {
+			return &StageError{Stage: stage, Attempts: attempt, Err: err}
+		}
+
+		delay := policy.Backoff(attempt)
+		log.Printf("stage %s failed on attempt %d, retrying after %s: %v", stage, attempt, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return &StageError{Stage: stage, Attempts: attempt, Err: ctx.Err()}
+		}
+	}
+}
+
+// runStageOnce executes stage function when there is free slot of stage limit.
+func (p *Pipeline) runStageOnce(ctx context.Context, stage watcher.Stage, fn func() error) error {
+	if limit, ok := p.limits[stage]; ok {
+		select {
+		case limit <- struct{}{}:
+			defer func() { <-limit }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	counter := p.inFlight[stage]
+	counter.Add(1)
+	defer counter.Add(-1)
+
+	return fn()
+}
+
+func (p *Pipeline) updateStatus(job *Job, stage watcher.Stage, err error) {
+	if storeErr := p.statusServ.Store.UpdateStage(job.Document, job.Version, stage, err); storeErr != nil {
+		log.Printf("failed to update status of file %s: %v", job.FilePath, storeErr)
+	}
+
+	event := &watcher.StageEvent{
+		FolderID:     job.Document.FolderID,
+		DocumentPath: job.Document.DocumentPath,
+		DocumentName: job.Document.DocumentName,
+		Version:      job.Version,
+		Stage:        stage,
+		Timestamp:    time.Now().UTC().Format(time.RFC3339Nano),
+		Document:     job.Document,
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	p.events.Publish(event)
+}
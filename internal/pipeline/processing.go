@@ -0,0 +1,270 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"doc-watcher/internal/searcher"
+	"doc-watcher/internal/watcher"
+)
+
+func (p *Pipeline) processJob(ctx context.Context, job *Job) error {
+	switch job.Action {
+	case ActionRemove:
+		return p.removeDocument(ctx, job)
+	default:
+		var err error
+		if job.Action == ActionReembed {
+			err = p.reembedDocument(ctx, job)
+		} else {
+			err = p.storeDocument(ctx, job)
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if err != nil {
+			job.Document.SetQuality(0)
+			p.updateStatus(job, watcher.StageFailed, err)
+		} else {
+			p.updateStatus(job, watcher.StageDone, nil)
+		}
+		return err
+	}
+}
+
+func (p *Pipeline) storeDocument(ctx context.Context, job *Job) error {
+	document := job.Document
+
+	var data []byte
+	err := p.runStage(ctx, job, watcher.StageDownloading, func() (err error) {
+		data, err = p.loader.LoadFile(ctx, job)
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to load file data: %w", err)
+	}
+
+	err = p.runStage(ctx, job, watcher.StageOcr, func() error {
+		return p.ocrServ.Ocr.RecognizeFile(ctx, document, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to recognize file: %w", err)
+	}
+
+	if err = p.recognizeDocument(ctx, job); err != nil {
+		return err
+	}
+
+	p.indexDocument(ctx, job)
+	return nil
+}
+
+// reembedDocument computes vectors of document loaded from searcher, so
+// file is not downloaded and recognized again. Files which documents are
+// not stored into searcher are processed from the beginning.
+func (p *Pipeline) reembedDocument(ctx context.Context, job *Job) error {
+	document := job.Document
+	indexed, ok := p.index.Get(document.FolderID, document.DocumentPath)
+	if !ok || !p.searchServ.StoresDocuments() {
+		return p.storeDocument(ctx, job)
+	}
+
+	var stored *watcher.Document
+	err := p.runStage(ctx, job, watcher.StageDownloading, func() (err error) {
+		stored, err = p.searchServ.FetchDocument(ctx, indexed.DocumentFolder(), indexed.DocumentID)
+		return err
+	})
+
+	if errors.Is(err, searcher.ErrDocumentNotFound) {
+		log.Printf("doc %s of file %s is not stored, processing file again", indexed.DocumentID, job.FilePath)
+		return p.storeDocument(ctx, job)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to load stored doc: %w", err)
+	}
+
+	stored.SetFolderID(document.FolderID)
+	*document = *stored
+
+	if err = p.recognizeDocument(ctx, job); err != nil {
+		return err
+	}
+
+	p.indexDocument(ctx, job)
+	return nil
+}
+
+// indexDocument stores indexed object of stored document and deletes
+// previous documents of object replaced by it. Document reembedded into
+// target folder keeps previous one of its own folder until object is
+// changed, then documents of both folders are replaced.
+func (p *Pipeline) indexDocument(ctx context.Context, job *Job) {
+	folderID := job.Document.FolderID
+	doc := job.StoredDocument()
+
+	previous, err := p.index.Store(folderID, doc, job.ETag)
+	if err != nil {
+		log.Printf("failed to store indexed object %s: %v", job.FilePath, err)
+		return
+	}
+
+	if previous == nil {
+		return
+	}
+
+	for _, stored := range previous.Documents() {
+		switch {
+		case stored.FolderID == doc.FolderID && stored.DocumentID == doc.DocumentID:
+			p.deleteLeftoverChunks(stored, len(doc.Embeddings))
+		case stored.FolderID == folderID && doc.FolderID != folderID:
+			// Document of object folder is kept while object is reembedded.
+		default:
+			log.Printf("deleting previous doc %s of changed file %s", stored.DocumentID, job.FilePath)
+			chunksCount := p.storedChunksCount(ctx, stored)
+			if err = p.searchServ.Delete(stored.FolderID, stored.DocumentID, chunksCount); err != nil {
+				log.Printf("failed to delete previous doc %s: %v", stored.DocumentID, err)
+			}
+		}
+	}
+}
+
+// deleteLeftoverChunks deletes chunks of previous document which are out
+// of chunks count of document computed again, e.g. by another model.
+func (p *Pipeline) deleteLeftoverChunks(previous *watcher.StoredDocument, chunksCount int) {
+	if previous.ChunksCount <= chunksCount {
+		return
+	}
+
+	if err := p.searchServ.DeleteChunks(previous.FolderID, previous.DocumentID, chunksCount, previous.ChunksCount); err != nil {
+		log.Printf("failed to delete leftover chunks of doc %s: %v", previous.DocumentID, err)
+	}
+}
+
+// storedChunksCount returns chunks count of stored document. Objects indexed
+// before chunks have been counted have zero count, then it is taken from
+// vectors of document kept into main index.
+func (p *Pipeline) storedChunksCount(ctx context.Context, stored *watcher.StoredDocument) int {
+	if stored.ChunksCount > 0 || !p.searchServ.StoresChunks() || !p.searchServ.StoresDocuments() {
+		return stored.ChunksCount
+	}
+
+	doc, err := p.searchServ.FetchDocument(ctx, stored.FolderID, stored.DocumentID)
+	if err != nil {
+		if !errors.Is(err, searcher.ErrDocumentNotFound) {
+			log.Printf("failed to load chunks count of doc %s: %v", stored.DocumentID, err)
+		}
+		return 0
+	}
+
+	return len(doc.Embeddings)
+}
+
+func (p *Pipeline) recognizeDocument(ctx context.Context, job *Job) error {
+	doc := job.Document
+	doc.ComputeMd5Hash()
+	doc.ComputeSsdeepHash()
+	doc.SetEmbeddings([]*watcher.Embeddings{})
+
+	if err := p.embedDocument(ctx, job); err != nil {
+		return err
+	}
+
+	log.Println("storing doc to searcher: ", doc.DocumentName)
+	err := p.runStage(ctx, job, watcher.StageStoring, func() error {
+		return p.searchServ.Store(job.StoredDocument())
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to store doc %s: %w", doc.DocumentName, err)
+	}
+
+	return nil
+}
+
+// embedDocument computes vectors of document chunks unless documents
+// are stored without vectors.
+func (p *Pipeline) embedDocument(ctx context.Context, job *Job) error {
+	doc := job.Document
+	if !p.searchServ.StoresVectors() {
+		// Documents stored without vectors have no model, so reembedding
+		// skips them.
+		doc.SetEmbeddingsModel("", 0)
+		return nil
+	}
+
+	log.Printf("loading embeddings for doc %s: ", doc.DocumentName)
+	err := p.runStage(ctx, job, watcher.StageEmbedding, func() error {
+		model, err := p.tokenServ.Tokenizer.Model(ctx)
+		if err != nil {
+			return err
+		}
+
+		tokenVectors, err := p.tokenServ.Tokenizer.Tokenize(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		dimension := 0
+		if len(tokenVectors.Vectors) > 0 {
+			dimension = len(tokenVectors.Vectors[0])
+		}
+
+		doc.SetEmbeddingsModel(model, dimension)
+		doc.SetEmbeddings([]*watcher.Embeddings{})
+		for chunkID, chunkData := range tokenVectors.Vectors {
+			text := tokenVectors.ChunkedText[chunkID]
+			embedding := doc.AppendContentVector(text, chunkData)
+			if chunkID < len(tokenVectors.Offsets) {
+				embedding.PageNumber = doc.PageNumberAt(tokenVectors.Offsets[chunkID])
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to load embeddings for doc %s: %w", doc.DocumentName, err)
+	}
+
+	return nil
+}
+
+// removeDocument deletes document of removed file from searcher
+// indexes to keep them consistent with watched directory contents.
+func (p *Pipeline) removeDocument(ctx context.Context, job *Job) error {
+	folderID := job.Document.FolderID
+	filePath := job.Document.DocumentPath
+
+	// Status is deleted at last, so it is not restored by stage updates.
+	defer func() {
+		if err := p.statusServ.Store.Delete(job.Document, job.Version); err != nil {
+			log.Printf("failed to delete status of file %s: %v", job.FilePath, err)
+		}
+	}()
+
+	indexed, ok := p.index.Get(folderID, filePath)
+	if !ok {
+		log.Printf("removed file %s has not been indexed, its doc is not deleted from searcher", job.FilePath)
+		return nil
+	}
+
+	// Documents of all folders are deleted, like document kept into object
+	// folder while object is stored into target folder of reembedding.
+	for _, stored := range indexed.Documents() {
+		chunksCount := p.storedChunksCount(ctx, stored)
+		err := p.runStage(ctx, job, watcher.StageStoring, func() error {
+			return p.searchServ.Delete(stored.FolderID, stored.DocumentID, chunksCount)
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to delete doc %s: %w", stored.DocumentID, err)
+		}
+	}
+
+	return p.index.Delete(folderID, filePath)
+}
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/watcher"
+)
+
+// StageError is returned when stage has failed after all attempts.
+type StageError struct {
+	Stage    watcher.Stage
+	Attempts int
+	Err      error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s failed after %d attempts: %v", e.Stage, e.Attempts, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent returns true for deterministic failures like empty recognized
+// content or malformed responses, which are the same on each attempt.
+func IsPermanent(err error) bool {
+	if errors.Is(err, ocr.ErrEmptyContent) {
+		return true
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"doc-watcher/internal/sender"
+)
+
+// Policy describes how failed request must be retried. Backoff
+// durations are set in milliseconds.
+type Policy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	Multiplier        float64
+	Jitter            float64
+	RetryableStatuses []int
+}
+
+// IsRetryable returns false for canceled context and for responses
+// with status which is not listed as retryable.
+func (p *Policy) IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *sender.StatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(p.RetryableStatuses, statusErr.StatusCode)
+	}
+
+	return true
+}
+
+// Backoff returns delay before next attempt with exponential growth and
+// randomized jitter to avoid synchronous retries of parallel workers.
+func (p *Policy) Backoff(attempt int) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	delay := float64(p.InitialBackoff*time.Millisecond) * math.Pow(multiplier, float64(attempt-1))
+
+	maxBackoff := float64(p.MaxBackoff * time.Millisecond)
+	if maxBackoff > 0 && delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(delay)
+}
@@ -3,4 +3,8 @@ package searcher
 type Config struct {
 	Address   string
 	EnableSSL bool
+	// StorageMode is a way to store documents: combined, text, vectors or split.
+	StorageMode string
+	// Concurrency is a number of chunks vectors stored at once.
+	Concurrency int
 }
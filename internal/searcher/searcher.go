@@ -2,10 +2,14 @@ package searcher
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"doc-watcher/internal/sender"
@@ -15,16 +19,138 @@ import (
 
 const DocumentJsonMime = echo.MIMEApplicationJSON
 
+const (
+	// StorageCombined stores documents with their vectors into main index.
+	StorageCombined = "combined"
+	// StorageText stores documents into main index without vectors.
+	StorageText = "text"
+	// StorageVectors stores only chunks vectors into vectors index.
+	StorageVectors = "vectors"
+	// StorageSplit stores documents without vectors into main index and
+	// chunks vectors into vectors index.
+	StorageSplit = "split"
+)
+
+// ErrDocumentNotFound is returned when searcher has no requested document.
+var ErrDocumentNotFound = errors.New("document is not found")
+
 type Service struct {
 	config *Config
 }
 
 func New(config *Config) *Service {
+	switch config.StorageMode {
+	case StorageCombined, StorageText, StorageVectors, StorageSplit:
+	case "":
+		config.StorageMode = StorageCombined
+	default:
+		log.Fatalln("unknown searcher storage mode: ", config.StorageMode)
+	}
+
 	return &Service{
 		config: config,
 	}
 }
 
+// StoresVectors returns false if documents are stored without vectors,
+// so computing embeddings may be skipped.
+func (s *Service) StoresVectors() bool {
+	return s.config.StorageMode != StorageText
+}
+
+// StoresDocuments returns false if only chunks vectors are stored, so
+// documents may not be loaded from searcher.
+func (s *Service) StoresDocuments() bool {
+	return s.config.StorageMode != StorageVectors
+}
+
+// StoresChunks returns true if chunks vectors are stored into vectors
+// index apart from documents.
+func (s *Service) StoresChunks() bool {
+	mode := s.config.StorageMode
+	return mode == StorageVectors || mode == StorageSplit
+}
+
+// Store stores document into main and vectors indexes by storage mode.
+func (s *Service) Store(doc *watcher.Document) error {
+	mode := s.config.StorageMode
+	if mode == StorageCombined {
+		return s.StoreDocument(doc)
+	}
+
+	if mode == StorageText || mode == StorageSplit {
+		if err := s.StoreDocument(doc.WithoutEmbeddings()); err != nil {
+			return err
+		}
+	}
+
+	if mode == StorageVectors || mode == StorageSplit {
+		return s.storeVectors(doc.VectorRecords())
+	}
+
+	return nil
+}
+
+// storeVectors stores chunks vectors concurrently bounded by config.
+func (s *Service) storeVectors(records []*watcher.VectorRecord) error {
+	recordErrs := make([]error, len(records))
+	limit := make(chan struct{}, max(s.config.Concurrency, 1))
+
+	wg := &sync.WaitGroup{}
+	for index, record := range records {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+
+			if err := s.StoreVector(record); err != nil {
+				recordErrs[index] = fmt.Errorf("failed to store chunk %d: %w", record.ChunkIndex, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(recordErrs...)
+}
+
+// Delete deletes document and its chunks vectors stored by storage mode.
+// Document which has been deleted already is not an error.
+func (s *Service) Delete(folderID, documentID string, chunksCount int) error {
+	if s.config.StorageMode != StorageVectors {
+		if err := s.DeleteDocument(folderID, documentID); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	return s.DeleteChunks(folderID, documentID, 0, chunksCount)
+}
+
+// DeleteChunks deletes vectors of document chunks from passed index up to
+// chunks count. Chunks which have been deleted already are skipped.
+func (s *Service) DeleteChunks(folderID, documentID string, from, chunksCount int) error {
+	if !s.StoresChunks() {
+		return nil
+	}
+
+	for index := from; index < chunksCount; index++ {
+		chunkID := watcher.ChunkID(documentID, index)
+		err := s.DeleteVector(folderID, chunkID)
+		if err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete chunk %d: %w", index, err)
+		}
+	}
+
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var statusErr *sender.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
 func (s *Service) StoreDocument(doc *watcher.Document) error {
 	jsonData, err := json.Marshal(doc)
 	if err != nil {
@@ -52,13 +178,43 @@ func (s *Service) StoreDocument(doc *watcher.Document) error {
 	return nil
 }
 
-func (s *Service) StoreVector(doc *watcher.Document) error {
-	jsonData, err := json.Marshal(doc)
+// FetchDocument loads document stored into main index.
+func (s *Service) FetchDocument(ctx context.Context, folderID, documentID string) (*watcher.Document, error) {
+	buildURL := strings.Builder{}
+	buildURL.WriteString(sender.GetHttpSchema(s.config.EnableSSL))
+	buildURL.WriteString("://")
+	buildURL.WriteString(s.config.Address)
+	buildURL.WriteString("/storage/folders/")
+	buildURL.WriteString(folderID)
+	buildURL.WriteString("/documents/")
+	buildURL.WriteString(documentID)
+	targetURL := buildURL.String()
+
+	timeoutReq := time.Duration(300) * time.Second
+	respData, err := sender.GET(ctx, targetURL, timeoutReq)
+	if isNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", ErrDocumentNotFound, err)
+	}
+
 	if err != nil {
-		return fmt.Errorf("failed while marshaling doc: %w", err)
+		return nil, err
+	}
+
+	doc := &watcher.Document{}
+	if err = json.Unmarshal(respData, doc); err != nil {
+		return nil, fmt.Errorf("failed to decode stored document: %w", err)
 	}
 
-	folderID := fmt.Sprintf("%s-vector", doc.FolderID)
+	return doc, nil
+}
+
+func (s *Service) StoreVector(record *watcher.VectorRecord) error {
+	jsonData, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed while marshaling vector: %w", err)
+	}
+
+	folderID := fmt.Sprintf("%s-vector", record.FolderID)
 
 	buildURL := strings.Builder{}
 	buildURL.WriteString(sender.GetHttpSchema(s.config.EnableSSL))
@@ -70,7 +226,7 @@ func (s *Service) StoreVector(doc *watcher.Document) error {
 	buildURL.WriteString("?folder_type=vectors")
 	targetURL := buildURL.String()
 
-	log.Printf("storing document %s to index %s", doc.DocumentID, doc.FolderID)
+	log.Printf("storing chunk %s of document %s to index %s", record.DocumentID, record.ParentID, folderID)
 
 	reqBody := bytes.NewBuffer(jsonData)
 	timeoutReq := time.Duration(300) * time.Second
@@ -81,3 +237,50 @@ func (s *Service) StoreVector(doc *watcher.Document) error {
 
 	return nil
 }
+
+func (s *Service) DeleteDocument(folderID, documentID string) error {
+	buildURL := strings.Builder{}
+	buildURL.WriteString(sender.GetHttpSchema(s.config.EnableSSL))
+	buildURL.WriteString("://")
+	buildURL.WriteString(s.config.Address)
+	buildURL.WriteString("/storage/folders/")
+	buildURL.WriteString(folderID)
+	buildURL.WriteString("/documents/")
+	buildURL.WriteString(documentID)
+	targetURL := buildURL.String()
+
+	log.Printf("deleting document %s from index %s", documentID, folderID)
+
+	timeoutReq := time.Duration(300) * time.Second
+	_, err := sender.DELETE(targetURL, timeoutReq)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteVector(folderID, documentID string) error {
+	vectorFolderID := fmt.Sprintf("%s-vector", folderID)
+
+	buildURL := strings.Builder{}
+	buildURL.WriteString(sender.GetHttpSchema(s.config.EnableSSL))
+	buildURL.WriteString("://")
+	buildURL.WriteString(s.config.Address)
+	buildURL.WriteString("/storage/folders/")
+	buildURL.WriteString(vectorFolderID)
+	buildURL.WriteString("/documents/")
+	buildURL.WriteString(documentID)
+	buildURL.WriteString("?folder_type=vectors")
+	targetURL := buildURL.String()
+
+	log.Printf("deleting document %s from index %s", documentID, vectorFolderID)
+
+	timeoutReq := time.Duration(300) * time.Second
+	_, err := sender.DELETE(targetURL, timeoutReq)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package sender
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,17 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// StatusError is returned when service responded with non success status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("non success response %s: %s", e.Status, e.Body)
+}
+
 func PUT(body *bytes.Buffer, url, mime string, timeout time.Duration) ([]byte, error) {
 	req, err := http.NewRequest(http.MethodPut, url, body)
 	if err != nil {
@@ -21,8 +33,8 @@ func PUT(body *bytes.Buffer, url, mime string, timeout time.Duration) ([]byte, e
 	return SendRequest(client, req)
 }
 
-func POST(body *bytes.Buffer, url, mime string, timeout time.Duration) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodPost, url, body)
+func POST(ctx context.Context, body *bytes.Buffer, url, mime string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -32,6 +44,26 @@ func POST(body *bytes.Buffer, url, mime string, timeout time.Duration) ([]byte,
 	return SendRequest(client, req)
 }
 
+func GET(ctx context.Context, url string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	return SendRequest(client, req)
+}
+
+func DELETE(url string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	return SendRequest(client, req)
+}
+
 func SendRequest(client *http.Client, req *http.Request) ([]byte, error) {
 	response, err := client.Do(req)
 	if err != nil {
@@ -44,8 +76,12 @@ func SendRequest(client *http.Client, req *http.Request) ([]byte, error) {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if response.StatusCode > 200 {
-		return nil, fmt.Errorf("non success response %s: %s", response.Status, string(respData))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, &StatusError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       string(respData),
+		}
 	}
 
 	return respData, nil
@@ -0,0 +1,46 @@
+package httpserv
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Service) CreateEmbeddingsGroup() error {
+	group := s.server.Group("/embeddings")
+
+	group.GET("/cache", s.FetchCacheStats)
+	group.DELETE("/cache", s.PurgeCache)
+
+	return nil
+}
+
+// FetchCacheStats
+// @Summary Fetch embeddings cache stats
+// @Description Load cached vectors count and cache hits since start
+// @ID embeddings-cache-stats
+// @Tags embeddings
+// @Produce json
+// @Success 200 {object} embeddings.CacheStats "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /embeddings/cache [get]
+func (s *Service) FetchCacheStats(c echo.Context) error {
+	return c.JSON(200, s.cache.Stats())
+}
+
+// PurgeCache
+// @Summary Purge embeddings cache
+// @Description Delete all cached vectors and reset cache stats
+// @ID embeddings-cache-purge
+// @Tags embeddings
+// @Produce json
+// @Success 200 {object} ResponseForm "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /embeddings/cache [delete]
+func (s *Service) PurgeCache(c echo.Context) error {
+	if err := s.cache.Purge(); err != nil {
+		return err
+	}
+
+	return c.JSON(200, createStatusResponse(200, "Ok"))
+}
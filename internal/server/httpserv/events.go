@@ -0,0 +1,81 @@
+package httpserv
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	eventsBufferSize  = 256
+	keepAliveInterval = 15 * time.Second
+)
+
+// StreamEvents
+// @Summary Stream processing events
+// @Description Push Server-Sent Event each time document changes processing stage
+// @ID watcher-events
+// @Tags watcher
+// @Produce text/event-stream
+// @Param bucket query string false "Folder id to filter events"
+// @Param file query string false "File name or path to filter events"
+// @Success 200 {object} watcher.StageEvent "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/events [get]
+func (s *Service) StreamEvents(c echo.Context) error {
+	bucket := c.QueryParam("bucket")
+	file := c.QueryParam("file")
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	resp.Header().Set(echo.HeaderConnection, "keep-alive")
+	resp.WriteHeader(200)
+	resp.Flush()
+
+	sub := s.events.Subscribe(eventsBufferSize)
+	defer s.events.Unsubscribe(sub)
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if _, err := fmt.Fprint(resp, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			resp.Flush()
+
+		case event, ok := <-sub.Events:
+			if !ok {
+				return nil
+			}
+
+			if len(bucket) > 0 && event.FolderID != bucket {
+				continue
+			}
+
+			if len(file) > 0 && event.DocumentName != file && event.DocumentPath != file {
+				continue
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+
+			if _, err = fmt.Fprintf(resp, "event: stage\ndata: %s\n\n", data); err != nil {
+				return nil
+			}
+			resp.Flush()
+		}
+	}
+}
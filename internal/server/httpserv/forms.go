@@ -31,3 +31,25 @@ type AttachDirectoryForm struct {
 type FetchDocumentsList struct {
 	FileNames []string `json:"file_names" example:"test-file.docx"`
 }
+
+// FailedDocumentsList example
+type FailedDocumentsList struct {
+	JobIDs []string `json:"job_ids" example:"886f7e11-874f-4c4e-b5e6-6e3a2e4f6c2a"`
+	All    bool     `json:"all" example:"false"`
+}
+
+// ReembedForm example
+type ReembedForm struct {
+	TargetFolder string `json:"target_folder" example:"test-folder-v2"`
+	Force        bool   `json:"force" example:"false"`
+	Rate         int    `json:"rate" example:"120"`
+	MaxPending   int    `json:"max_pending" example:"4"`
+}
+
+// WebhookForm example
+type WebhookForm struct {
+	URL     string   `json:"url" example:"http://localhost:8080/hooks/documents"`
+	Events  []string `json:"events" example:"done,failed"`
+	Buckets []string `json:"buckets" example:"test-folder"`
+	Secret  string   `json:"secret" example:"signing-secret"`
+}
@@ -2,6 +2,8 @@ package httpserv
 
 import (
 	"encoding/json"
+
+	"doc-watcher/internal/watcher"
 	"github.com/labstack/echo/v4"
 )
 
@@ -14,6 +16,16 @@ func (s *Service) CreateWatcherGroup() error {
 	group.DELETE("/:bucket/detach", s.DetachDirectory)
 	group.POST("/processing/fetch", s.FetchProcessingDocuments)
 	group.POST("/processing/clean", s.CleanProcessingDocuments)
+	group.GET("/queue", s.FetchQueueStats)
+	group.GET("/events", s.StreamEvents)
+	group.GET("/documents/:bucket/*", s.FetchDocumentStatus)
+	group.POST("/:bucket/backfill", s.BackfillDirectory)
+	group.GET("/backfill", s.FetchBackfillProgress)
+	group.POST("/:bucket/reembed", s.ReembedDirectory)
+	group.GET("/reembed", s.FetchReembedProgress)
+	group.GET("/failed", s.FetchFailedDocuments)
+	group.POST("/failed/requeue", s.RequeueFailedDocuments)
+	group.POST("/failed/clean", s.CleanFailedDocuments)
 
 	return nil
 }
@@ -30,7 +42,10 @@ func (s *Service) CreateWatcherGroup() error {
 // @Router /watcher/run [get]
 func (s *Service) RunWatchers(c echo.Context) error {
 	ctx := c.Request().Context()
-	s.watcher.Watcher.RunWatchers(ctx)
+	if err := s.watcher.Watcher.RunWatchers(ctx); err != nil {
+		return err
+	}
+
 	return c.JSON(200, createStatusResponse(200, "Ok"))
 }
 
@@ -46,7 +61,10 @@ func (s *Service) RunWatchers(c echo.Context) error {
 // @Router /watcher/stop [get]
 func (s *Service) StopWatchers(c echo.Context) error {
 	ctx := c.Request().Context()
-	s.watcher.Watcher.TerminateWatchers(ctx)
+	if err := s.watcher.Watcher.TerminateWatchers(ctx); err != nil {
+		return err
+	}
+
 	return c.JSON(200, createStatusResponse(200, "Ok"))
 }
 
@@ -151,3 +169,225 @@ func (s *Service) CleanProcessingDocuments(c echo.Context) error {
 
 	return c.JSON(200, createStatusResponse(200, "Ok"))
 }
+
+// FetchDocumentStatus
+// @Summary Fetch document processing status
+// @Description Load current stage, stages timeline and processing details of document
+// @ID fetch-document-status
+// @Tags watcher
+// @Produce json
+// @Param bucket path string true "Folder id"
+// @Param key path string true "Document path into folder"
+// @Param version query string false "Object version, the latest processed version if empty"
+// @Success 200 {object} watcher.DocumentStatus "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	404 {object} BadRequestForm "Document not found"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/documents/{bucket}/{key} [get]
+func (s *Service) FetchDocumentStatus(c echo.Context) error {
+	bucket := c.Param("bucket")
+	documentPath := c.Param("*")
+	version := c.QueryParam("version")
+
+	ctx := c.Request().Context()
+	record, err := s.watcher.Watcher.FetchDocumentStatus(ctx, bucket, documentPath, version)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return c.JSON(404, createStatusResponse(404, "there is no such document"))
+	}
+
+	return c.JSON(200, record)
+}
+
+// FetchQueueStats
+// @Summary Fetch processing queue stats
+// @Description Load processing queue depth and count of jobs in flight by stages
+// @ID fetch-queue
+// @Tags watcher
+// @Produce json
+// @Success 200 {object} watcher.QueueStats "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/queue [get]
+func (s *Service) FetchQueueStats(c echo.Context) error {
+	ctx := c.Request().Context()
+	stats := s.watcher.Watcher.FetchQueueStats(ctx)
+	return c.JSON(200, stats)
+}
+
+// BackfillDirectory
+// @Summary Backfill directory
+// @Description Process all existing files of directory which have not been indexed yet
+// @ID folders-backfill
+// @Tags watcher
+// @Produce json
+// @Param bucket path string true "Folder id"
+// @Success 200 {object} ResponseForm "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/{bucket}/backfill [post]
+func (s *Service) BackfillDirectory(c echo.Context) error {
+	bucket := c.Param("bucket")
+
+	ctx := c.Request().Context()
+	if err := s.watcher.Watcher.BackfillDirectory(ctx, bucket); err != nil {
+		return err
+	}
+
+	return c.JSON(200, createStatusResponse(200, "Ok"))
+}
+
+// FetchBackfillProgress
+// @Summary Fetch backfill progress
+// @Description Load progress of launched directories backfill
+// @ID fetch-backfill
+// @Tags watcher
+// @Produce json
+// @Success 200 {object} []watcher.BackfillProgress "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/backfill [get]
+func (s *Service) FetchBackfillProgress(c echo.Context) error {
+	ctx := c.Request().Context()
+	progress := s.watcher.Watcher.FetchBackfillProgress(ctx)
+	return c.JSON(200, progress)
+}
+
+// ReembedDirectory
+// @Summary Reembed directory
+// @Description Compute vectors of indexed files by current embeddings model
+// @Description and store them into the same or target index
+// @ID folders-reembed
+// @Tags watcher
+// @Accept  json
+// @Produce json
+// @Param bucket path string true "Folder id"
+// @Param jsonQuery body ReembedForm true "Reembedding options"
+// @Success 200 {object} ResponseForm "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/{bucket}/reembed [post]
+func (s *Service) ReembedDirectory(c echo.Context) error {
+	bucket := c.Param("bucket")
+
+	jsonForm := &ReembedForm{}
+	decoder := json.NewDecoder(c.Request().Body)
+	if err := decoder.Decode(jsonForm); err != nil {
+		return err
+	}
+
+	options := &watcher.ReembedOptions{
+		TargetFolder: jsonForm.TargetFolder,
+		Force:        jsonForm.Force,
+		Rate:         jsonForm.Rate,
+		MaxPending:   jsonForm.MaxPending,
+	}
+
+	ctx := c.Request().Context()
+	if err := s.watcher.Watcher.ReembedDirectory(ctx, bucket, options); err != nil {
+		return c.JSON(400, createStatusResponse(400, err.Error()))
+	}
+
+	return c.JSON(200, createStatusResponse(200, "Ok"))
+}
+
+// FetchReembedProgress
+// @Summary Fetch reembedding progress
+// @Description Load progress of launched directories reembedding
+// @ID fetch-reembed
+// @Tags watcher
+// @Produce json
+// @Success 200 {object} []watcher.ReembedProgress "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/reembed [get]
+func (s *Service) FetchReembedProgress(c echo.Context) error {
+	ctx := c.Request().Context()
+	progress := s.watcher.Watcher.FetchReembedProgress(ctx)
+	return c.JSON(200, progress)
+}
+
+// FetchFailedDocuments
+// @Summary Fetch failed documents
+// @Description Load documents which have been permanently failed while processing
+// @ID fetch-failed
+// @Tags watcher
+// @Produce json
+// @Success 200 {object} []watcher.FailedDocument "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/failed [get]
+func (s *Service) FetchFailedDocuments(c echo.Context) error {
+	ctx := c.Request().Context()
+	documents, err := s.watcher.Watcher.FetchFailedDocuments(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, documents)
+}
+
+// RequeueFailedDocuments
+// @Summary Requeue failed documents
+// @Description Push failed documents back to processing queue. All documents are requeued if all flag is set
+// @ID requeue-failed
+// @Tags watcher
+// @Accept  json
+// @Produce json
+// @Param jsonQuery body FailedDocumentsList true "Job ids of failed documents to requeue"
+// @Success 200 {object} ResponseForm "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/failed/requeue [post]
+func (s *Service) RequeueFailedDocuments(c echo.Context) error {
+	jsonForm := &FailedDocumentsList{}
+	decoder := json.NewDecoder(c.Request().Body)
+	if err := decoder.Decode(jsonForm); err != nil {
+		return err
+	}
+
+	if len(jsonForm.JobIDs) == 0 && !jsonForm.All {
+		return c.JSON(400, createStatusResponse(400, "job ids or all flag must be passed"))
+	}
+
+	ctx := c.Request().Context()
+	if err := s.watcher.Watcher.RequeueFailedDocuments(ctx, jsonForm.JobIDs, jsonForm.All); err != nil {
+		return err
+	}
+
+	return c.JSON(200, createStatusResponse(200, "Ok"))
+}
+
+// CleanFailedDocuments
+// @Summary Clean failed documents
+// @Description Remove failed documents from list. All documents are removed if all flag is set
+// @ID clean-failed
+// @Tags watcher
+// @Accept  json
+// @Produce json
+// @Param jsonQuery body FailedDocumentsList true "Job ids of failed documents to clean"
+// @Success 200 {object} ResponseForm "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /watcher/failed/clean [post]
+func (s *Service) CleanFailedDocuments(c echo.Context) error {
+	jsonForm := &FailedDocumentsList{}
+	decoder := json.NewDecoder(c.Request().Body)
+	if err := decoder.Decode(jsonForm); err != nil {
+		return err
+	}
+
+	if len(jsonForm.JobIDs) == 0 && !jsonForm.All {
+		return c.JSON(400, createStatusResponse(400, "job ids or all flag must be passed"))
+	}
+
+	ctx := c.Request().Context()
+	if err := s.watcher.Watcher.CleanFailedDocuments(ctx, jsonForm.JobIDs, jsonForm.All); err != nil {
+		return err
+	}
+
+	return c.JSON(200, createStatusResponse(200, "Ok"))
+}
@@ -3,8 +3,11 @@ package httpserv
 import (
 	"context"
 
+	"doc-watcher/internal/embeddings/cache"
+	"doc-watcher/internal/events"
 	"doc-watcher/internal/server"
 	"doc-watcher/internal/watcher"
+	"doc-watcher/internal/webhooks"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
@@ -13,15 +16,27 @@ import (
 )
 
 type Service struct {
-	config  *server.Config
-	server  *echo.Echo
-	watcher *watcher.Service
+	config   *server.Config
+	server   *echo.Echo
+	watcher  *watcher.Service
+	events   *events.Broker
+	webhooks *webhooks.Service
+	cache    *cache.Service
 }
 
-func New(servConf *server.Config, nw *watcher.Service) *server.Server {
+func New(
+	servConf *server.Config,
+	nw *watcher.Service,
+	eb *events.Broker,
+	wh *webhooks.Service,
+	ec *cache.Service,
+) *server.Server {
 	httpServer := &Service{
-		config:  servConf,
-		watcher: nw,
+		config:   servConf,
+		watcher:  nw,
+		events:   eb,
+		webhooks: wh,
+		cache:    ec,
 	}
 
 	return &server.Server{
@@ -37,6 +52,8 @@ func (s *Service) setupServer() {
 	s.server.Use(InitLogger(s.config))
 
 	_ = s.CreateWatcherGroup()
+	_ = s.CreateWebhooksGroup()
+	_ = s.CreateEmbeddingsGroup()
 
 	s.server.GET("/swagger/*", echoSwagger.WrapHandler)
 }
@@ -0,0 +1,97 @@
+package httpserv
+
+import (
+	"encoding/json"
+	"errors"
+
+	"doc-watcher/internal/watcher"
+	"doc-watcher/internal/webhooks"
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Service) CreateWebhooksGroup() error {
+	group := s.server.Group("/webhooks")
+
+	group.GET("", s.FetchWebhooks)
+	group.PUT("", s.CreateWebhook)
+	group.DELETE("/:id", s.DeleteWebhook)
+
+	return nil
+}
+
+// FetchWebhooks
+// @Summary Fetch webhooks
+// @Description Load all webhook subscriptions without signing secrets
+// @ID webhooks-fetch
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} []webhooks.Subscription "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /webhooks [get]
+func (s *Service) FetchWebhooks(c echo.Context) error {
+	return c.JSON(200, s.webhooks.List())
+}
+
+// CreateWebhook
+// @Summary Create webhook
+// @Description Subscribe url to finished or failed documents events
+// @ID webhooks-create
+// @Tags webhooks
+// @Accept  json
+// @Produce json
+// @Param jsonQuery body WebhookForm true "Webhook subscription"
+// @Success 200 {object} webhooks.Subscription "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /webhooks [put]
+func (s *Service) CreateWebhook(c echo.Context) error {
+	jsonForm := &WebhookForm{}
+	decoder := json.NewDecoder(c.Request().Body)
+	if err := decoder.Decode(jsonForm); err != nil {
+		return err
+	}
+
+	events := make([]watcher.Stage, len(jsonForm.Events))
+	for index, event := range jsonForm.Events {
+		events[index] = watcher.Stage(event)
+	}
+
+	sub, err := s.webhooks.Create(&webhooks.Subscription{
+		URL:     jsonForm.URL,
+		Events:  events,
+		Buckets: jsonForm.Buckets,
+		Secret:  jsonForm.Secret,
+	})
+
+	if err != nil {
+		return c.JSON(400, createStatusResponse(400, err.Error()))
+	}
+
+	return c.JSON(200, sub)
+}
+
+// DeleteWebhook
+// @Summary Delete webhook
+// @Description Delete webhook subscription by id
+// @ID webhooks-delete
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook id"
+// @Success 200 {object} ResponseForm "Ok"
+// @Failure	400 {object} BadRequestForm "Bad Request message"
+// @Failure	404 {object} BadRequestForm "Webhook not found"
+// @Failure	503 {object} ServerErrorForm "Server does not available"
+// @Router /webhooks/{id} [delete]
+func (s *Service) DeleteWebhook(c echo.Context) error {
+	err := s.webhooks.Delete(c.Param("id"))
+	if errors.Is(err, webhooks.ErrNotFound) {
+		return c.JSON(404, createStatusResponse(404, err.Error()))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, createStatusResponse(200, "Ok"))
+}
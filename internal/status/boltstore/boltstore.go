@@ -0,0 +1,195 @@
+package boltstore
+
+import (
+	"encoding/json"
+	"log"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"doc-watcher/internal/status"
+	"doc-watcher/internal/storage"
+	"doc-watcher/internal/watcher"
+)
+
+const statusBucket = "processing-status"
+
+type Service struct {
+	mu    sync.Mutex
+	store *storage.Service
+}
+
+func New(store *storage.Service) *status.Service {
+	servClient := &Service{
+		store: store,
+	}
+
+	return &status.Service{
+		Store: servClient,
+	}
+}
+
+func (s *Service) UpdateStage(doc *watcher.Document, version string, stage watcher.Stage, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := statusKey(doc.FolderID, doc.DocumentPath, version)
+	record := &watcher.DocumentStatus{}
+	exists, getErr := s.store.Get(statusBucket, key, record)
+	if getErr != nil {
+		return getErr
+	}
+
+	// Each new processing of document starts new stages timeline.
+	if !exists || stage == watcher.StageQueued {
+		record = &watcher.DocumentStatus{
+			FolderID:     doc.FolderID,
+			DocumentPath: doc.DocumentPath,
+			DocumentName: doc.DocumentName,
+			Version:      version,
+			Transitions:  make([]*watcher.StageTransition, 0),
+		}
+	}
+
+	now := time.Now().UTC()
+	transition := &watcher.StageTransition{
+		Stage:     stage,
+		Timestamp: now.Format(time.RFC3339Nano),
+	}
+
+	// Duration of previous stage is known when next stage begins.
+	if count := len(record.Transitions); count > 0 {
+		previous := record.Transitions[count-1]
+		if startedAt, parseErr := time.Parse(time.RFC3339Nano, previous.Timestamp); parseErr == nil {
+			previous.DurationMs = now.Sub(startedAt).Milliseconds()
+		}
+	}
+
+	record.Stage = stage
+	record.DocumentID = doc.DocumentID
+	record.DocumentSSDEEP = doc.DocumentSSDEEP
+	record.ChunksCount = len(doc.Embeddings)
+	record.Quality = doc.QualityRecognized
+	if doc.OcrMetadata != nil {
+		record.PagesCount = doc.OcrMetadata.PagesCount
+		record.Recognizer = doc.OcrMetadata.Recognizer
+		record.LowQuality = doc.OcrMetadata.LowQuality
+	}
+
+	record.Error = ""
+	if err != nil {
+		record.Error = err.Error()
+		transition.Error = err.Error()
+	}
+
+	record.UpdatedAt = transition.Timestamp
+	record.Transitions = append(record.Transitions, transition)
+	return s.store.Put(statusBucket, key, record)
+}
+
+// Fetch returns status of document version. Documents of versioned
+// buckets are stored by versions, so status of the latest updated
+// version is returned if version is not passed.
+func (s *Service) Fetch(folderID, documentPath, version string) (*watcher.DocumentStatus, bool, error) {
+	record := &watcher.DocumentStatus{}
+	key := statusKey(folderID, documentPath, version)
+	exists, err := s.store.Get(statusBucket, key, record)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if exists {
+		return record, true, nil
+	}
+
+	if len(version) > 0 {
+		return nil, false, nil
+	}
+
+	return s.fetchLatestVersion(key)
+}
+
+func (s *Service) fetchLatestVersion(key string) (*watcher.DocumentStatus, bool, error) {
+	var latest *watcher.DocumentStatus
+	err := s.store.ForEachPrefix(statusBucket, key+"@", func(versionKey string, data []byte) error {
+		record := &watcher.DocumentStatus{}
+		if err := json.Unmarshal(data, record); err != nil {
+			log.Printf("failed to unmarshal status %s: %v", versionKey, err)
+			return nil
+		}
+
+		if latest == nil || latest.UpdatedAt < record.UpdatedAt {
+			latest = record
+		}
+
+		return nil
+	})
+
+	if err != nil || latest == nil {
+		return nil, false, err
+	}
+
+	return latest, true, nil
+}
+
+func (s *Service) FetchByNames(names []string) ([]*watcher.DocumentStatus, error) {
+	records := make([]*watcher.DocumentStatus, 0)
+	err := s.store.ForEach(statusBucket, func(key string, data []byte) error {
+		record := &watcher.DocumentStatus{}
+		if err := json.Unmarshal(data, record); err != nil {
+			log.Printf("failed to unmarshal status %s: %v", key, err)
+			return nil
+		}
+
+		if slices.Contains(names, record.DocumentName) {
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+func (s *Service) DeleteByNames(names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0)
+	err := s.store.ForEach(statusBucket, func(key string, data []byte) error {
+		record := &watcher.DocumentStatus{}
+		if err := json.Unmarshal(data, record); err != nil {
+			return nil
+		}
+
+		if slices.Contains(names, record.DocumentName) {
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return s.store.Delete(statusBucket, keys...)
+}
+
+func (s *Service) Delete(doc *watcher.Document, version string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := statusKey(doc.FolderID, doc.DocumentPath, version)
+	return s.store.Delete(statusBucket, key)
+}
+
+func statusKey(folderID, documentPath, version string) string {
+	key := path.Join(folderID, documentPath)
+	if len(version) > 0 {
+		key = key + "@" + version
+	}
+
+	return key
+}
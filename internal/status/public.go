@@ -0,0 +1,15 @@
+package status
+
+import "doc-watcher/internal/watcher"
+
+type Service struct {
+	Store StatusStore
+}
+
+type StatusStore interface {
+	UpdateStage(doc *watcher.Document, version string, stage watcher.Stage, err error) error
+	Fetch(folderID, documentPath, version string) (*watcher.DocumentStatus, bool, error)
+	FetchByNames(names []string) ([]*watcher.DocumentStatus, error)
+	DeleteByNames(names []string) error
+	Delete(doc *watcher.Document, version string) error
+}
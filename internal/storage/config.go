@@ -0,0 +1,8 @@
+package storage
+
+import "time"
+
+type Config struct {
+	Path    string
+	Timeout time.Duration
+}
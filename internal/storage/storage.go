@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Service is an embedded key-value database shared by all services
+// which must keep their state between restarts.
+type Service struct {
+	db *bolt.DB
+}
+
+func New(config *Config) *Service {
+	if err := os.MkdirAll(filepath.Dir(config.Path), os.ModePerm); err != nil {
+		log.Fatalln("failed to create storage directory: ", err)
+	}
+
+	opts := &bolt.Options{Timeout: config.Timeout * time.Second}
+	db, err := bolt.Open(config.Path, 0600, opts)
+	if err != nil {
+		log.Fatalln("failed to open storage file: ", err)
+	}
+
+	return &Service{db: db}
+}
+
+func (s *Service) Close() error {
+	return s.db.Close()
+}
+
+func (s *Service) Put(bucket, key string, value any) error {
+	return s.Update(func(tx *Tx) error {
+		return tx.Put(bucket, key, value)
+	})
+}
+
+// Get unmarshals stored value into passed value and returns false
+// if there is no value by passed key.
+func (s *Service) Get(bucket, key string, value any) (bool, error) {
+	var found bool
+	err := s.View(func(tx *Tx) error {
+		var err error
+		found, err = tx.Get(bucket, key, value)
+		return err
+	})
+
+	return found, err
+}
+
+func (s *Service) Delete(bucket string, keys ...string) error {
+	return s.Update(func(tx *Tx) error {
+		return tx.Delete(bucket, keys...)
+	})
+}
+
+// Tx is a transaction over several buckets.
+type Tx struct {
+	tx *bolt.Tx
+}
+
+// View runs passed function by read only transaction.
+func (s *Service) View(fn func(tx *Tx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+// Update runs passed function by single read-write transaction, its
+// changes are applied at once or discarded if function returns error.
+func (s *Service) Update(fn func(tx *Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+func (t *Tx) Put(bucket, key string, value any) error {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed while marshaling value: %w", err)
+	}
+
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+	}
+
+	return b.Put([]byte(key), jsonData)
+}
+
+// Get unmarshals stored value into passed value and returns false
+// if there is no value by passed key.
+func (t *Tx) Get(bucket, key string, value any) (bool, error) {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return false, nil
+	}
+
+	jsonData := b.Get([]byte(key))
+	if jsonData == nil {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(jsonData, value); err != nil {
+		return false, fmt.Errorf("failed while unmarshaling value: %w", err)
+	}
+
+	return true, nil
+}
+
+func (t *Tx) Delete(bucket string, keys ...string) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := b.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForEach calls passed function for each stored value of bucket. Passed
+// data is valid only while function is running.
+func (s *Service) ForEach(bucket string, fn func(key string, data []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// ForEachPrefix calls passed function for each stored value of bucket
+// which key starts with passed prefix.
+func (s *Service) ForEachPrefix(bucket, prefix string, fn func(key string, data []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		cursor := b.Cursor()
+		for k, v := cursor.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = cursor.Next() {
+			if err := fn(string(k), v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteBucket deletes bucket with all its values.
+func (s *Service) DeleteBucket(bucket string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket([]byte(bucket))
+		if errors.Is(err, bolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
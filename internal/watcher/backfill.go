@@ -0,0 +1,74 @@
+package watcher
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type BackfillProgress struct {
+	Directory  string `json:"directory"`
+	Listed     int    `json:"listed"`
+	Processed  int    `json:"processed"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	Done       bool   `json:"done"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// BackfillTracker stores progress of directories backfilling
+// which may be fetched concurrently by http server.
+type BackfillTracker struct {
+	mu       sync.RWMutex
+	progress map[string]*BackfillProgress
+}
+
+func NewBackfillTracker() *BackfillTracker {
+	return &BackfillTracker{
+		progress: make(map[string]*BackfillProgress),
+	}
+}
+
+func (bt *BackfillTracker) Begin(dir string) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	bt.progress[dir] = &BackfillProgress{
+		Directory: dir,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func (bt *BackfillTracker) Update(dir string, fn func(progress *BackfillProgress)) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	if progress, ok := bt.progress[dir]; ok {
+		fn(progress)
+	}
+}
+
+func (bt *BackfillTracker) Finish(dir string) {
+	bt.Update(dir, func(progress *BackfillProgress) {
+		progress.Done = true
+		progress.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	})
+}
+
+func (bt *BackfillTracker) Snapshot() []*BackfillProgress {
+	bt.mu.RLock()
+	defer bt.mu.RUnlock()
+
+	snapshot := make([]*BackfillProgress, 0, len(bt.progress))
+	for _, progress := range bt.progress {
+		progressCopy := *progress
+		snapshot = append(snapshot, &progressCopy)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Directory < snapshot[j].Directory
+	})
+
+	return snapshot
+}
@@ -1,13 +1,21 @@
 package watcher
 
-import "time"
-
 type Config struct {
+	// Backend is a watched storage: minio buckets or local directories.
+	Backend            string
 	Address            string
 	Username           string
 	Password           string
 	EnableSSL          bool
 	WatchedDirectories []string
-	CacheExpire        time.Duration
-	CacheCleanInterval time.Duration
+	Backfill           bool
+	Reembed            ReembedConfig
+}
+
+// ReembedConfig is a default throttling of reembedding jobs.
+type ReembedConfig struct {
+	// Rate limits enqueued documents per minute, 0 disables limit.
+	Rate int
+	// MaxPending limits documents being reembedded at the same time.
+	MaxPending int
 }
@@ -0,0 +1,173 @@
+package watcher
+
+import (
+	"encoding/json"
+	"log"
+	"path"
+	"time"
+
+	"doc-watcher/internal/storage"
+)
+
+const indexedObjectsBucket = "indexed-objects"
+
+type IndexedObject struct {
+	FolderID   string `json:"folder_id"`
+	ObjectPath string `json:"object_path"`
+	ETag       string `json:"etag"`
+	DocumentID string `json:"document_id"`
+	IndexedAt  string `json:"indexed_at"`
+	// StoredFolder is a folder storing document if it differs from
+	// object folder, like target folder of reembedding.
+	StoredFolder string `json:"stored_folder,omitempty"`
+	// SourceDocument is a document kept into object folder while
+	// document is stored into another folder.
+	SourceDocument *StoredDocument `json:"source_document,omitempty"`
+
+	EmbeddingsModel     string `json:"embeddings_model"`
+	EmbeddingsDimension int    `json:"embeddings_dimension"`
+	ChunksCount         int    `json:"chunks_count"`
+}
+
+// StoredDocument is a document of indexed object stored into searcher folder.
+type StoredDocument struct {
+	FolderID    string `json:"folder_id"`
+	DocumentID  string `json:"document_id"`
+	ChunksCount int    `json:"chunks_count"`
+}
+
+// Documents returns all documents of object stored into searcher.
+func (o *IndexedObject) Documents() []*StoredDocument {
+	documents := []*StoredDocument{{
+		FolderID:    o.DocumentFolder(),
+		DocumentID:  o.DocumentID,
+		ChunksCount: o.ChunksCount,
+	}}
+
+	if o.SourceDocument != nil {
+		documents = append(documents, o.SourceDocument)
+	}
+
+	return documents
+}
+
+// DocumentFolder returns folder storing document of object.
+func (o *IndexedObject) DocumentFolder() string {
+	if len(o.StoredFolder) > 0 {
+		return o.StoredFolder
+	}
+
+	return o.FolderID
+}
+
+// ObjectsIndex keeps track of already indexed objects to skip them
+// while directory is being backfilled.
+type ObjectsIndex struct {
+	store *storage.Service
+}
+
+func NewObjectsIndex(store *storage.Service) *ObjectsIndex {
+	return &ObjectsIndex{store: store}
+}
+
+func (oi *ObjectsIndex) Get(folderID, objectPath string) (*IndexedObject, bool) {
+	object := &IndexedObject{}
+	ok, err := oi.store.Get(indexedObjectsBucket, objectKey(folderID, objectPath), object)
+	if err != nil {
+		log.Printf("failed to load indexed object %s: %v", objectPath, err)
+		return nil, false
+	}
+
+	return object, ok
+}
+
+func (oi *ObjectsIndex) IsIndexed(folderID, objectPath, etag string) bool {
+	object, ok := oi.Get(folderID, objectPath)
+	return ok && len(etag) > 0 && object.ETag == etag
+}
+
+// Store indexes document of object from passed folder and returns previous
+// indexed object if it exists. Document may be stored into another folder
+// while folder is reembedded, then document of object folder is kept as
+// source one until object is changed.
+func (oi *ObjectsIndex) Store(folderID string, doc *Document, etag string) (*IndexedObject, error) {
+	object := &IndexedObject{
+		FolderID:   folderID,
+		ObjectPath: doc.DocumentPath,
+		ETag:       etag,
+		DocumentID: doc.DocumentID,
+		IndexedAt:  time.Now().UTC().Format(time.RFC3339),
+
+		EmbeddingsModel:     doc.EmbeddingsModel,
+		EmbeddingsDimension: doc.EmbeddingsDimension,
+		ChunksCount:         len(doc.Embeddings),
+	}
+
+	var previous *IndexedObject
+	err := oi.store.Update(func(tx *storage.Tx) error {
+		key := objectKey(folderID, doc.DocumentPath)
+
+		stored := &IndexedObject{}
+		found, err := tx.Get(indexedObjectsBucket, key, stored)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			previous = stored
+		}
+
+		if doc.FolderID != folderID {
+			object.StoredFolder = doc.FolderID
+			object.SourceDocument = sourceDocument(previous)
+		}
+
+		return tx.Put(indexedObjectsBucket, key, object)
+	})
+
+	return previous, err
+}
+
+// sourceDocument returns document of object folder kept by previous
+// indexed object.
+func sourceDocument(previous *IndexedObject) *StoredDocument {
+	switch {
+	case previous == nil:
+		return nil
+	case len(previous.StoredFolder) == 0:
+		return &StoredDocument{
+			FolderID:    previous.FolderID,
+			DocumentID:  previous.DocumentID,
+			ChunksCount: previous.ChunksCount,
+		}
+	default:
+		return previous.SourceDocument
+	}
+}
+
+// List returns all indexed objects of folder.
+func (oi *ObjectsIndex) List(folderID string) ([]*IndexedObject, error) {
+	objects := make([]*IndexedObject, 0)
+	err := oi.store.ForEach(indexedObjectsBucket, func(key string, data []byte) error {
+		object := &IndexedObject{}
+		if err := json.Unmarshal(data, object); err != nil {
+			log.Printf("failed to unmarshal indexed object %s: %v", key, err)
+			return nil
+		}
+
+		if object.FolderID == folderID {
+			objects = append(objects, object)
+		}
+		return nil
+	})
+
+	return objects, err
+}
+
+func (oi *ObjectsIndex) Delete(folderID, objectPath string) error {
+	return oi.store.Delete(indexedObjectsBucket, objectKey(folderID, objectPath))
+}
+
+func objectKey(folderID, objectPath string) string {
+	return path.Join(folderID, objectPath)
+}
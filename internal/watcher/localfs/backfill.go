@@ -0,0 +1,108 @@
+package localfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"doc-watcher/internal/watcher"
+)
+
+func (lw *LocalFS) BackfillDirectory(_ context.Context, dir string) error {
+	dir = filepath.Clean(dir)
+	if _, ok := lw.bindDirs.Load(dir); !ok {
+		return errors.New("there is no such directory to backfill")
+	}
+
+	go lw.backfillDirectory(context.Background(), dir)
+	return nil
+}
+
+func (lw *LocalFS) FetchBackfillProgress(_ context.Context) []*watcher.BackfillProgress {
+	return lw.backfills.Snapshot()
+}
+
+// backfillDirectory enqueues all files which have been stored into directory
+// before watcher has been launched and have not been indexed yet.
+func (lw *LocalFS) backfillDirectory(ctx context.Context, dir string) {
+	log.Printf("launching backfill of directory %s", dir)
+
+	lw.backfills.Begin(dir)
+	defer lw.backfills.Finish(dir)
+
+	wg := &sync.WaitGroup{}
+	onDone := func(err error) {
+		defer wg.Done()
+		lw.backfills.Update(dir, func(progress *watcher.BackfillProgress) {
+			if err != nil {
+				progress.Failed++
+			} else {
+				progress.Processed++
+			}
+		})
+	}
+
+	folderID := folderID(dir)
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		lw.backfills.Update(dir, func(progress *watcher.BackfillProgress) {
+			progress.Listed++
+		})
+
+		relPath, _ := filepath.Rel(dir, filePath)
+		if lw.index.IsIndexed(folderID, relPath, fileETag(info)) {
+			lw.backfills.Update(dir, func(progress *watcher.BackfillProgress) {
+				progress.Skipped++
+			})
+			return nil
+		}
+
+		wg.Add(1)
+		if procErr := lw.enqueueFile(ctx, dir, filePath, onDone); procErr != nil {
+			log.Printf("failed to enqueue file %s: %v", filePath, procErr)
+			onDone(procErr)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("failed to walk directory %s: %v", dir, err)
+	}
+
+	awaitBackfill(ctx, wg)
+
+	log.Printf("backfill of directory %s has been finished", dir)
+}
+
+func awaitBackfill(ctx context.Context, wg *sync.WaitGroup) {
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,19 @@
+package localfs
+
+import (
+	"context"
+
+	"doc-watcher/internal/watcher"
+)
+
+func (lw *LocalFS) FetchFailedDocuments(_ context.Context) ([]*watcher.FailedDocument, error) {
+	return lw.pipe.FailedDocuments()
+}
+
+func (lw *LocalFS) RequeueFailedDocuments(ctx context.Context, jobIDs []string, all bool) error {
+	return lw.pipe.RequeueFailed(ctx, jobIDs, all)
+}
+
+func (lw *LocalFS) CleanFailedDocuments(_ context.Context, jobIDs []string, all bool) error {
+	return lw.pipe.CleanFailed(jobIDs, all)
+}
@@ -0,0 +1,99 @@
+package localfs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/watcher"
+)
+
+func (lw *LocalFS) enqueueFile(ctx context.Context, dir, filePath string, onDone func(err error)) error {
+	job, err := newFileJob(pipeline.ActionStore, dir, filePath)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("caught event with file %s into directory %s", job.Document.DocumentPath, dir)
+
+	job.OnDone = onDone
+	return lw.pipe.Enqueue(ctx, job)
+}
+
+func newFileJob(action pipeline.Action, dir, filePath string) (*pipeline.Job, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	document, err := buildDocument(dir, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	modifiedAt := info.ModTime().UTC().Format(time.RFC3339)
+	document.DocumentSize = info.Size()
+	document.DocumentPermissions = int32(info.Mode().Perm())
+	document.DocumentModified = modifiedAt
+	document.DocumentCreated = modifiedAt
+
+	return pipeline.NewJob(action, filePath, fileETag(info), document), nil
+}
+
+func (lw *LocalFS) enqueueRemovedFile(ctx context.Context, dir, filePath string) error {
+	document, err := buildDocument(dir, filePath)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := lw.index.Get(document.FolderID, document.DocumentPath); !ok {
+		// Removed entry may be a directory or not indexed file.
+		return nil
+	}
+
+	log.Printf("caught remove event with file %s into directory %s", document.DocumentPath, dir)
+
+	job := pipeline.NewJob(pipeline.ActionRemove, filePath, "", document)
+	return lw.pipe.Enqueue(ctx, job)
+}
+
+func (lw *LocalFS) LoadFile(_ context.Context, job *pipeline.Job) ([]byte, error) {
+	return os.ReadFile(job.FilePath)
+}
+
+func buildDocument(dir, filePath string) (*watcher.Document, error) {
+	relPath, err := filepath.Rel(dir, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve file path: %w", err)
+	}
+
+	fileName := filepath.Base(relPath)
+	fileExt := filepath.Ext(fileName)
+
+	document := &watcher.Document{}
+	document.FolderID = folderID(dir)
+	document.FolderPath = filepath.Dir(relPath)
+	document.DocumentPath = relPath
+	document.DocumentName = fileName
+	document.DocumentType = watcher.ParseDocumentType(fileExt)
+	document.DocumentExtension = fileExt
+	document.QualityRecognized = -1
+
+	return document, nil
+}
+
+// folderID returns folder of directory used by searcher indexes, status
+// and objects index keys. Watched directories must have distinct folders.
+func folderID(dir string) string {
+	return filepath.Base(dir)
+}
+
+// fileETag builds pseudo ETag of local file from its size and
+// modification time to detect changed files while backfilling.
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+}
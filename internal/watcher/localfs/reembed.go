@@ -0,0 +1,78 @@
+package localfs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/watcher"
+)
+
+func (lw *LocalFS) ReembedDirectory(ctx context.Context, dir string, options *watcher.ReembedOptions) error {
+	dir = filepath.Clean(dir)
+	if _, ok := lw.bindDirs.Load(dir); !ok {
+		return errors.New("there is no such directory to reembed")
+	}
+
+	objects, err := lw.index.List(folderID(dir))
+	if err != nil {
+		return err
+	}
+
+	if options.Rate <= 0 {
+		options.Rate = lw.config.Reembed.Rate
+	}
+
+	if options.MaxPending <= 0 {
+		options.MaxPending = lw.config.Reembed.MaxPending
+	}
+
+	lw.mu.Lock()
+	running, reembedCtx := lw.running, lw.reembedCtx
+	lw.mu.Unlock()
+
+	if !running {
+		return watcher.ErrWatchersStopped
+	}
+
+	model, err := lw.pipe.EmbeddingsModel(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !lw.reembeds.Begin(dir, options, model) {
+		return errors.New("directory is being reembedded already")
+	}
+
+	go watcher.Reembed(reembedCtx, dir, objects, options, model, lw.reembeds,
+		func(object *watcher.IndexedObject, onDone func(err error)) error {
+			return lw.enqueueReembed(reembedCtx, dir, object, options.TargetFolder, onDone)
+		},
+	)
+
+	return nil
+}
+
+func (lw *LocalFS) FetchReembedProgress(_ context.Context) []*watcher.ReembedProgress {
+	return lw.reembeds.Snapshot()
+}
+
+func (lw *LocalFS) enqueueReembed(
+	ctx context.Context,
+	dir string,
+	object *watcher.IndexedObject,
+	targetFolder string,
+	onDone func(err error),
+) error {
+	filePath := filepath.Join(dir, object.ObjectPath)
+	job, err := newFileJob(pipeline.ActionReembed, dir, filePath)
+	if err != nil {
+		return err
+	}
+
+	job.TargetFolder = targetFolder
+	job.OnDone = onDone
+
+	return lw.pipe.Enqueue(ctx, job)
+}
@@ -0,0 +1,397 @@
+package localfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/events"
+	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/searcher"
+	"doc-watcher/internal/status"
+	"doc-watcher/internal/storage"
+	"doc-watcher/internal/watcher"
+	"github.com/fsnotify/fsnotify"
+)
+
+const BackendName = "localfs"
+
+// settleDelay is a time to wait after last write event before file
+// will be processed. It prevents processing partially copied files.
+const settleDelay = 2 * time.Second
+
+type LocalFS struct {
+	config    *watcher.Config
+	bindDirs  *sync.Map
+	listeners *sync.WaitGroup
+
+	pipe      *pipeline.Pipeline
+	index     *watcher.ObjectsIndex
+	backfills *watcher.BackfillTracker
+	reembeds  *watcher.ReembedTracker
+	status    *status.Service
+
+	// mu guards running state, so watchers are launched and stopped once
+	// and listeners are not attached while watchers are being stopped.
+	mu           sync.Mutex
+	running      bool
+	stopPipeline func()
+
+	// reembedCtx is cancelled on shutdown to stop running reembeddings.
+	reembedCtx     context.Context
+	cancelReembeds context.CancelFunc
+}
+
+func New(
+	config *watcher.Config,
+	pipeConfig *pipeline.Config,
+	ocrServ *ocr.Service,
+	searchServ *searcher.Service,
+	tokenServ *embeddings.Service,
+	statusServ *status.Service,
+	eventsBroker *events.Broker,
+	store *storage.Service,
+) *watcher.Service {
+	folders := make(map[string]string)
+	for _, dir := range config.WatchedDirectories {
+		dir = filepath.Clean(dir)
+		if other, ok := folders[folderID(dir)]; ok && other != dir {
+			log.Fatalln("watched directories have the same folder id: ", other, dir)
+		}
+		folders[folderID(dir)] = dir
+	}
+
+	objectsIndex := watcher.NewObjectsIndex(store)
+	bindDirs := &sync.Map{}
+
+	watcherInst := &LocalFS{
+		config:    config,
+		bindDirs:  bindDirs,
+		listeners: &sync.WaitGroup{},
+
+		index:     objectsIndex,
+		backfills: watcher.NewBackfillTracker(),
+		reembeds:  watcher.NewReembedTracker(),
+		status:    statusServ,
+	}
+
+	watcherInst.pipe = pipeline.New(
+		pipeConfig,
+		watcherInst,
+		statusServ,
+		eventsBroker,
+		ocrServ,
+		searchServ,
+		tokenServ,
+		store,
+	)
+
+	return &watcher.Service{Watcher: watcherInst}
+}
+
+// RunWatchers launches pipeline and directories listeners.
+func (lw *LocalFS) RunWatchers(_ context.Context) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.running {
+		return watcher.ErrWatchersRunning
+	}
+
+	pipeCtx, cancel := context.WithCancel(context.Background())
+	pipeDone := make(chan struct{})
+	go func() {
+		defer close(pipeDone)
+		lw.pipe.Run(pipeCtx)
+	}()
+
+	lw.running = true
+	lw.stopPipeline = func() {
+		cancel()
+		<-pipeDone
+	}
+	lw.reembedCtx, lw.cancelReembeds = context.WithCancel(context.Background())
+
+	for _, dir := range lw.config.WatchedDirectories {
+		if err := lw.attachDirectory(dir); err != nil {
+			log.Printf("failed to attach directory %s: %v", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// TerminateWatchers stops watchers and waits for pipeline workers,
+// so storage may be closed after it.
+func (lw *LocalFS) TerminateWatchers(_ context.Context) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if !lw.running {
+		return watcher.ErrWatchersStopped
+	}
+	lw.running = false
+
+	// Listeners are stopped before pipeline, so they do not
+	// enqueue caught events into stopped queue.
+	lw.stopListeners()
+	lw.cancelReembeds()
+	lw.stopPipeline()
+	return nil
+}
+
+func (lw *LocalFS) stopListeners() {
+	lw.bindDirs.Range(func(_, value any) bool {
+		cancel := value.(context.CancelFunc)
+		cancel()
+		return true
+	})
+
+	lw.listeners.Wait()
+}
+
+func (lw *LocalFS) GetWatchedDirs(_ context.Context) ([]string, error) {
+	dirs := make([]string, 0)
+	lw.bindDirs.Range(func(key, _ any) bool {
+		dirs = append(dirs, key.(string))
+		return true
+	})
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func (lw *LocalFS) AttachDirectory(_ context.Context, dir string) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if !lw.running {
+		return watcher.ErrWatchersStopped
+	}
+
+	return lw.attachDirectory(dir)
+}
+
+// attachDirectory launches listener of directory, running state
+// lock must be held by caller.
+func (lw *LocalFS) attachDirectory(dir string) error {
+	dir = filepath.Clean(dir)
+	_, ok := lw.bindDirs.Load(dir)
+	if ok {
+		return errors.New("directory already attached")
+	}
+
+	if other, ok := lw.findFolder(folderID(dir)); ok {
+		return fmt.Errorf("directory %s is attached with the same folder id", other)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to stat directory %s: %w", dir, err)
+	}
+
+	if !info.IsDir() {
+		return errors.New("there is no such directory to launch")
+	}
+
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create fs notifier: %w", err)
+	}
+
+	if err = addRecursive(notifier, dir); err != nil {
+		_ = notifier.Close()
+		return err
+	}
+
+	cCtx, cancel := context.WithCancel(context.Background())
+	lw.bindDirs.Store(dir, cancel)
+
+	lw.listeners.Add(1)
+	go func() {
+		defer func() {
+			lw.bindDirs.Delete(dir)
+			_ = notifier.Close()
+			lw.listeners.Done()
+		}()
+
+		if lw.config.Backfill {
+			lw.listeners.Add(1)
+			go func() {
+				defer lw.listeners.Done()
+				lw.backfillDirectory(cCtx, dir)
+			}()
+		}
+
+		lw.launchProcessEventLoop(cCtx, dir, notifier)
+	}()
+
+	return nil
+}
+
+// findFolder returns attached directory by its folder id.
+func (lw *LocalFS) findFolder(folder string) (string, bool) {
+	found := ""
+	lw.bindDirs.Range(func(key, _ any) bool {
+		if folderID(key.(string)) == folder {
+			found = key.(string)
+			return false
+		}
+		return true
+	})
+
+	return found, len(found) > 0
+}
+
+func (lw *LocalFS) DetachDirectory(_ context.Context, dir string) error {
+	ch, ok := lw.bindDirs.Load(filepath.Clean(dir))
+	if !ok {
+		return errors.New("there is no such directory to detach")
+	}
+
+	cancel := ch.(context.CancelFunc)
+	cancel()
+
+	dirPrefix := filepath.Clean(dir) + string(filepath.Separator)
+	lw.pipe.Cancel(func(job *pipeline.Job) bool {
+		return strings.HasPrefix(job.FilePath, dirPrefix)
+	})
+
+	return nil
+}
+
+func (lw *LocalFS) FetchProcessingDocuments(_ context.Context, files []string) *watcher.ProcessingDocuments {
+	records, err := lw.status.Store.FetchByNames(files)
+	if err != nil {
+		log.Printf("failed to fetch processing documents: %v", err)
+		return &watcher.ProcessingDocuments{}
+	}
+
+	return watcher.GroupProcessingDocuments(records)
+}
+
+func (lw *LocalFS) FetchQueueStats(_ context.Context) *watcher.QueueStats {
+	return lw.pipe.Stats()
+}
+
+// FetchDocumentStatus returns processing status of document or nil
+// if document has not been processed yet.
+func (lw *LocalFS) FetchDocumentStatus(
+	_ context.Context,
+	folderID, documentPath, version string,
+) (*watcher.DocumentStatus, error) {
+	record, _, err := lw.status.Store.Fetch(folderID, documentPath, version)
+	return record, err
+}
+
+func (lw *LocalFS) CleanProcessingDocuments(_ context.Context, files []string) error {
+	lw.pipe.Cancel(func(job *pipeline.Job) bool {
+		return slices.Contains(files, job.Document.DocumentName)
+	})
+
+	return lw.status.Store.DeleteByNames(files)
+}
+
+func (lw *LocalFS) launchProcessEventLoop(ctx context.Context, dir string, notifier *fsnotify.Watcher) {
+	readyCh := make(chan string)
+	pending := make(map[string]*time.Timer)
+	defer func() {
+		for _, timer := range pending {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case filePath := <-readyCh:
+			delete(pending, filePath)
+			if err := lw.enqueueFile(ctx, dir, filePath, nil); err != nil {
+				log.Printf("failed to enqueue file %s: %v", filePath, err)
+			}
+
+		case event, ok := <-notifier.Events:
+			if !ok {
+				return
+			}
+
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				if timer, exists := pending[event.Name]; exists {
+					timer.Stop()
+					delete(pending, event.Name)
+				}
+
+				if err := lw.enqueueRemovedFile(ctx, dir, event.Name); err != nil {
+					log.Printf("failed to enqueue file %s: %v", event.Name, err)
+				}
+				continue
+			}
+
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			info, err := os.Stat(event.Name)
+			if err != nil {
+				continue
+			}
+
+			if info.IsDir() {
+				if err = addRecursive(notifier, event.Name); err != nil {
+					log.Printf("failed to watch directory %s: %v", event.Name, err)
+				}
+				continue
+			}
+
+			if timer, exists := pending[event.Name]; exists {
+				timer.Reset(settleDelay)
+				continue
+			}
+
+			filePath := event.Name
+			pending[filePath] = time.AfterFunc(settleDelay, func() {
+				select {
+				case readyCh <- filePath:
+				case <-ctx.Done():
+				}
+			})
+
+		case err, ok := <-notifier.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("caught fs notifier error for %s: %v", dir, err)
+		}
+	}
+}
+
+func addRecursive(notifier *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if err = notifier.Add(path); err != nil {
+			return fmt.Errorf("failed to watch directory %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
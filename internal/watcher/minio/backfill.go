@@ -0,0 +1,96 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/watcher"
+	"github.com/minio/minio-go/v7"
+)
+
+func (mw *S3Minio) BackfillDirectory(ctx context.Context, dir string) error {
+	watcherDirs, err := mw.GetWatchedDirs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(watcherDirs, dir) {
+		return errors.New("there is no such bucket to backfill")
+	}
+
+	go mw.backfillBucket(context.Background(), dir)
+	return nil
+}
+
+func (mw *S3Minio) FetchBackfillProgress(_ context.Context) []*watcher.BackfillProgress {
+	return mw.backfills.Snapshot()
+}
+
+// backfillBucket enqueues all objects which have been stored into bucket
+// before watcher has been launched and have not been indexed yet.
+func (mw *S3Minio) backfillBucket(ctx context.Context, bucketName string) {
+	log.Printf("launching backfill of bucket %s", bucketName)
+
+	mw.backfills.Begin(bucketName)
+	defer mw.backfills.Finish(bucketName)
+
+	wg := &sync.WaitGroup{}
+	onDone := func(err error) {
+		defer wg.Done()
+		mw.backfills.Update(bucketName, func(progress *watcher.BackfillProgress) {
+			if err != nil {
+				progress.Failed++
+			} else {
+				progress.Processed++
+			}
+		})
+	}
+
+	opts := minio.ListObjectsOptions{Recursive: true}
+	for objInfo := range mw.mc.ListObjects(ctx, bucketName, opts) {
+		if objInfo.Err != nil {
+			log.Printf("failed to list objects of bucket %s: %v", bucketName, objInfo.Err)
+			mw.backfills.Update(bucketName, func(progress *watcher.BackfillProgress) {
+				progress.Failed++
+			})
+			break
+		}
+
+		mw.backfills.Update(bucketName, func(progress *watcher.BackfillProgress) {
+			progress.Listed++
+		})
+
+		if mw.index.IsIndexed(bucketName, objInfo.Key, objInfo.ETag) {
+			mw.backfills.Update(bucketName, func(progress *watcher.BackfillProgress) {
+				progress.Skipped++
+			})
+			continue
+		}
+
+		wg.Add(1)
+		if err := mw.enqueueObject(ctx, pipeline.ActionStore, bucketName, objInfo, onDone); err != nil {
+			log.Printf("failed to enqueue file %s: %v", objInfo.Key, err)
+			onDone(err)
+		}
+	}
+
+	awaitBackfill(ctx, wg)
+	log.Printf("backfill of bucket %s has been finished", bucketName)
+}
+
+func awaitBackfill(ctx context.Context, wg *sync.WaitGroup) {
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,19 @@
+package minio
+
+import (
+	"context"
+
+	"doc-watcher/internal/watcher"
+)
+
+func (mw *S3Minio) FetchFailedDocuments(_ context.Context) ([]*watcher.FailedDocument, error) {
+	return mw.pipe.FailedDocuments()
+}
+
+func (mw *S3Minio) RequeueFailedDocuments(ctx context.Context, jobIDs []string, all bool) error {
+	return mw.pipe.RequeueFailed(ctx, jobIDs, all)
+}
+
+func (mw *S3Minio) CleanFailedDocuments(_ context.Context, jobIDs []string, all bool) error {
+	return mw.pipe.CleanFailed(jobIDs, all)
+}
@@ -4,70 +4,107 @@ import (
 	"bytes"
 	"context"
 	"log"
+	"net/url"
 	"path"
+	"strings"
 	"time"
 
+	"doc-watcher/internal/pipeline"
 	"doc-watcher/internal/watcher"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/notification"
 )
 
-func (mw *S3Minio) extractAndStoreDocument(ctx context.Context, event notification.Info) {
+func (mw *S3Minio) handleEvent(ctx context.Context, event notification.Info) {
 	for _, record := range event.Records {
 		s3Object := record.S3
-
 		bucketName := s3Object.Bucket.Name
-		filePath := s3Object.Object.Key
-		fileSize := s3Object.Object.Size
-		fileName := path.Base(filePath)
-		fileExt := path.Ext(fileName)
-		folderPath := path.Dir(filePath)
-		filePath = path.Join(folderPath, fileName)
-		fileType := watcher.ParseDocumentType(s3Object.Object.ContentType)
-
-		log.Printf("caught event with file %s into bucket %s", filePath, bucketName)
-
-		createdAt := time.Now().UTC().Format(time.RFC3339)
-		modifiedAt := createdAt
-
-		document := &watcher.Document{}
-		document.FolderID = bucketName
-		document.FolderPath = folderPath
-		document.DocumentPath = filePath
-		document.DocumentName = fileName
-		document.DocumentSize = fileSize
-		document.DocumentType = fileType
-		document.DocumentExtension = fileExt
-		document.DocumentPermissions = int32(777)
-		document.DocumentModified = modifiedAt
-		document.DocumentCreated = createdAt
-		document.QualityRecognized = -1
-
-		mw.cacher.Set(fileName, document, mw.config.CacheExpire*time.Minute)
-
-		data, err := mw.downloadFile(ctx, bucketName, fileName)
+
+		// Object keys of bucket notifications are url-encoded.
+		objectKey, err := url.QueryUnescape(s3Object.Object.Key)
 		if err != nil {
-			document.QualityRecognized = 0
-			log.Printf("failed to load file data: %w", err)
-			continue
+			objectKey = s3Object.Object.Key
 		}
-		defer data.Reset()
 
-		document.SetQuality(0)
-		err = mw.ocrServ.Ocr.RecognizeFile(document, data.Bytes())
-		if err != nil {
-			log.Printf("failed to recognize file: %w", err)
-			return
+		objInfo := minio.ObjectInfo{
+			Key:         objectKey,
+			Size:        s3Object.Object.Size,
+			ETag:        s3Object.Object.ETag,
+			ContentType: s3Object.Object.ContentType,
+			VersionID:   s3Object.Object.VersionID,
 		}
 
-		mw.recognizeDocument(document)
+		action := pipeline.ActionStore
+		if strings.HasPrefix(record.EventName, "s3:ObjectRemoved") {
+			action = pipeline.ActionRemove
+		}
+
+		log.Printf("caught %s event with file %s into bucket %s", action, objectKey, bucketName)
+
+		if err = mw.enqueueObject(ctx, action, bucketName, objInfo, nil); err != nil {
+			log.Printf("failed to enqueue file %s: %v", objectKey, err)
+		}
 	}
 }
 
-func (mw *S3Minio) downloadFile(ctx context.Context, bucket, filePath string) (bytes.Buffer, error) {
+func (mw *S3Minio) enqueueObject(
+	ctx context.Context,
+	action pipeline.Action,
+	bucketName string,
+	objInfo minio.ObjectInfo,
+	onDone func(err error),
+) error {
+	job := newObjectJob(action, bucketName, objInfo)
+	job.OnDone = onDone
+
+	return mw.pipe.Enqueue(ctx, job)
+}
+
+func newObjectJob(action pipeline.Action, bucketName string, objInfo minio.ObjectInfo) *pipeline.Job {
+	filePath := objInfo.Key
+	fileName := path.Base(filePath)
+	fileExt := path.Ext(fileName)
+	folderPath := path.Dir(filePath)
+	filePath = path.Join(folderPath, fileName)
+	fileType := watcher.ParseDocumentType(fileExt)
+
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	modifiedAt := createdAt
+
+	document := &watcher.Document{}
+	document.FolderID = bucketName
+	document.FolderPath = folderPath
+	document.DocumentPath = filePath
+	document.DocumentName = fileName
+	document.DocumentSize = objInfo.Size
+	document.DocumentType = fileType
+	document.DocumentExtension = fileExt
+	document.DocumentPermissions = int32(777)
+	document.DocumentModified = modifiedAt
+	document.DocumentCreated = createdAt
+	document.QualityRecognized = -1
+
+	job := pipeline.NewJob(action, objInfo.Key, objInfo.ETag, document)
+	job.Version = objInfo.VersionID
+	return job
+}
+
+func (mw *S3Minio) LoadFile(ctx context.Context, job *pipeline.Job) ([]byte, error) {
+	data, err := mw.downloadFile(ctx, job.Document.FolderID, job.FilePath, job.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// downloadFile downloads object of passed version, so downloaded data
+// matches version of recorded status. Latest version is downloaded
+// if version is not passed.
+func (mw *S3Minio) downloadFile(ctx context.Context, bucket, filePath, version string) (bytes.Buffer, error) {
 	var objBody bytes.Buffer
 
-	opts := minio.GetObjectOptions{}
+	opts := minio.GetObjectOptions{VersionID: version}
 	obj, err := mw.mc.GetObject(ctx, bucket, filePath, opts)
 	if err != nil {
 		return objBody, err
@@ -80,24 +117,3 @@ func (mw *S3Minio) downloadFile(ctx context.Context, bucket, filePath string) (b
 
 	return objBody, nil
 }
-
-func (mw *S3Minio) recognizeDocument(doc *watcher.Document) {
-	doc.ComputeMd5Hash()
-	doc.ComputeSsdeepHash()
-	doc.SetEmbeddings([]*watcher.Embeddings{})
-
-	log.Printf("loading embeddings for doc %s: ", doc.DocumentName)
-	tokenVectors, _ := mw.tokenServ.Tokenizer.Tokenize(doc)
-	for chunkID, chunkData := range tokenVectors.Vectors {
-		text := tokenVectors.ChunkedText[chunkID]
-		doc.AppendContentVector(text, chunkData)
-	}
-
-	log.Println("storing doc to searcher: ", doc.DocumentName)
-	if err := mw.searchServ.StoreDocument(doc); err != nil {
-		log.Printf("failed to store doc %s: %w", doc.DocumentName, err)
-		if obj, ok := mw.cacher.Get(doc.DocumentName); ok {
-			obj.(*watcher.Document).QualityRecognized = 0
-		}
-	}
-}
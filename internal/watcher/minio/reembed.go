@@ -0,0 +1,83 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"doc-watcher/internal/pipeline"
+	"doc-watcher/internal/watcher"
+	"github.com/minio/minio-go/v7"
+)
+
+func (mw *S3Minio) ReembedDirectory(ctx context.Context, dir string, options *watcher.ReembedOptions) error {
+	watcherDirs, err := mw.GetWatchedDirs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(watcherDirs, dir) {
+		return errors.New("there is no such bucket to reembed")
+	}
+
+	objects, err := mw.index.List(dir)
+	if err != nil {
+		return err
+	}
+
+	if options.Rate <= 0 {
+		options.Rate = mw.config.Reembed.Rate
+	}
+
+	if options.MaxPending <= 0 {
+		options.MaxPending = mw.config.Reembed.MaxPending
+	}
+
+	mw.mu.Lock()
+	running, reembedCtx := mw.running, mw.reembedCtx
+	mw.mu.Unlock()
+
+	if !running {
+		return watcher.ErrWatchersStopped
+	}
+
+	model, err := mw.pipe.EmbeddingsModel(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !mw.reembeds.Begin(dir, options, model) {
+		return errors.New("bucket is being reembedded already")
+	}
+
+	go watcher.Reembed(reembedCtx, dir, objects, options, model, mw.reembeds,
+		func(object *watcher.IndexedObject, onDone func(err error)) error {
+			return mw.enqueueReembed(reembedCtx, dir, object, options.TargetFolder, onDone)
+		},
+	)
+
+	return nil
+}
+
+func (mw *S3Minio) FetchReembedProgress(_ context.Context) []*watcher.ReembedProgress {
+	return mw.reembeds.Snapshot()
+}
+
+func (mw *S3Minio) enqueueReembed(
+	ctx context.Context,
+	bucketName string,
+	object *watcher.IndexedObject,
+	targetFolder string,
+	onDone func(err error),
+) error {
+	objInfo, err := mw.mc.StatObject(ctx, bucketName, object.ObjectPath, minio.StatObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	job := newObjectJob(pipeline.ActionReembed, bucketName, objInfo)
+	job.TargetFolder = targetFolder
+	job.OnDone = onDone
+
+	return mw.pipe.Enqueue(ctx, job)
+}
@@ -8,14 +8,19 @@ import (
 	"sync"
 
 	"doc-watcher/internal/embeddings"
+	"doc-watcher/internal/events"
 	"doc-watcher/internal/ocr"
+	"doc-watcher/internal/pipeline"
 	"doc-watcher/internal/searcher"
+	"doc-watcher/internal/status"
+	"doc-watcher/internal/storage"
 	"doc-watcher/internal/watcher"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"github.com/patrickmn/go-cache"
 )
 
+const BackendName = "minio"
+
 var (
 	prefix       = ""
 	suffix       = ""
@@ -26,23 +31,38 @@ var (
 )
 
 type S3Minio struct {
-	stopCh      chan bool
 	config      *watcher.Config
 	bindBuckets *sync.Map
+	listeners   *sync.WaitGroup
 
 	mc *minio.Client
 
-	cacher     *cache.Cache
-	ocrServ    *ocr.Service
-	searchServ *searcher.Service
-	tokenServ  *embeddings.Service
+	pipe      *pipeline.Pipeline
+	index     *watcher.ObjectsIndex
+	backfills *watcher.BackfillTracker
+	reembeds  *watcher.ReembedTracker
+	status    *status.Service
+
+	// mu guards running state, so watchers are launched and stopped once
+	// and listeners are not attached while watchers are being stopped.
+	mu           sync.Mutex
+	running      bool
+	stopPipeline func()
+
+	// reembedCtx is cancelled on shutdown to stop running reembeddings.
+	reembedCtx     context.Context
+	cancelReembeds context.CancelFunc
 }
 
 func New(
 	config *watcher.Config,
+	pipeConfig *pipeline.Config,
 	ocrServ *ocr.Service,
 	searchServ *searcher.Service,
 	tokenServ *embeddings.Service,
+	statusServ *status.Service,
+	eventsBroker *events.Broker,
+	store *storage.Service,
 ) *watcher.Service {
 	minioCreds := credentials.NewStaticV4(config.Username, config.Password, "")
 	minioOpts := &minio.Options{
@@ -55,32 +75,93 @@ func New(
 		log.Fatalln("failed to connect to minio cloud: ", err)
 	}
 
-	cacheServ := cache.New(config.CacheExpire, config.CacheCleanInterval)
+	objectsIndex := watcher.NewObjectsIndex(store)
 	bindBuckets := &sync.Map{}
 
 	watcherInst := &S3Minio{
-		stopCh:      make(chan bool),
 		config:      config,
 		bindBuckets: bindBuckets,
+		listeners:   &sync.WaitGroup{},
 
 		mc: mc,
 
-		cacher:     cacheServ,
-		ocrServ:    ocrServ,
-		searchServ: searchServ,
-		tokenServ:  tokenServ,
+		index:     objectsIndex,
+		backfills: watcher.NewBackfillTracker(),
+		reembeds:  watcher.NewReembedTracker(),
+		status:    statusServ,
 	}
 
+	watcherInst.pipe = pipeline.New(
+		pipeConfig,
+		watcherInst,
+		statusServ,
+		eventsBroker,
+		ocrServ,
+		searchServ,
+		tokenServ,
+		store,
+	)
+
 	return &watcher.Service{Watcher: watcherInst}
 }
 
-func (mw *S3Minio) RunWatchers(_ context.Context) {
-	go mw.launchProcessEventLoop()
-	<-mw.stopCh
+// RunWatchers launches pipeline and buckets listeners.
+func (mw *S3Minio) RunWatchers(_ context.Context) error {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	if mw.running {
+		return watcher.ErrWatchersRunning
+	}
+
+	pipeCtx, cancel := context.WithCancel(context.Background())
+	pipeDone := make(chan struct{})
+	go func() {
+		defer close(pipeDone)
+		mw.pipe.Run(pipeCtx)
+	}()
+
+	mw.running = true
+	mw.stopPipeline = func() {
+		cancel()
+		<-pipeDone
+	}
+	mw.reembedCtx, mw.cancelReembeds = context.WithCancel(context.Background())
+
+	for _, bucketName := range mw.config.WatchedDirectories {
+		mw.attachBucket(bucketName)
+	}
+
+	return nil
 }
 
-func (mw *S3Minio) TerminateWatchers(_ context.Context) {
-	mw.stopCh <- true
+// TerminateWatchers stops watchers and waits for pipeline workers,
+// so storage may be closed after it.
+func (mw *S3Minio) TerminateWatchers(_ context.Context) error {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	if !mw.running {
+		return watcher.ErrWatchersStopped
+	}
+	mw.running = false
+
+	// Listeners are stopped before pipeline, so they do not
+	// enqueue caught events into stopped queue.
+	mw.stopListeners()
+	mw.cancelReembeds()
+	mw.stopPipeline()
+	return nil
+}
+
+func (mw *S3Minio) stopListeners() {
+	mw.bindBuckets.Range(func(_, value any) bool {
+		cancel := value.(context.CancelFunc)
+		cancel()
+		return true
+	})
+
+	mw.listeners.Wait()
 }
 
 func (mw *S3Minio) GetWatchedDirs(ctx context.Context) ([]string, error) {
@@ -98,11 +179,6 @@ func (mw *S3Minio) GetWatchedDirs(ctx context.Context) ([]string, error) {
 }
 
 func (mw *S3Minio) AttachDirectory(ctx context.Context, dir string) error {
-	_, ok := mw.bindBuckets.Load(dir)
-	if ok {
-		return errors.New("directory already attached")
-	}
-
 	watcherDirs, err := mw.GetWatchedDirs(ctx)
 	if err != nil {
 		return err
@@ -112,21 +188,18 @@ func (mw *S3Minio) AttachDirectory(ctx context.Context, dir string) error {
 		return errors.New("there is no such bucket to launch")
 	}
 
-	go func() {
-		defer func() {
-			mw.bindBuckets.Delete(dir)
-		}()
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
 
-		cCtx, cancel := context.WithCancel(context.Background())
-		mw.bindBuckets.Store(dir, cancel)
+	if !mw.running {
+		return watcher.ErrWatchersStopped
+	}
 
-		for event := range mw.mc.ListenBucketNotification(cCtx, dir, prefix, suffix, eventsFilter) {
-			if event.Err == nil {
-				mw.extractAndStoreDocument(cCtx, event)
-			}
-		}
-	}()
+	if _, ok := mw.bindBuckets.Load(dir); ok {
+		return errors.New("directory already attached")
+	}
 
+	mw.attachBucket(dir)
 	return nil
 }
 
@@ -139,62 +212,76 @@ func (mw *S3Minio) DetachDirectory(_ context.Context, dir string) error {
 	cancel := ch.(context.CancelFunc)
 	cancel()
 
+	mw.pipe.Cancel(func(job *pipeline.Job) bool {
+		return job.Document.FolderID == dir
+	})
+
 	return nil
 }
 
 func (mw *S3Minio) FetchProcessingDocuments(_ context.Context, files []string) *watcher.ProcessingDocuments {
-	procDocs := &watcher.ProcessingDocuments{}
+	records, err := mw.status.Store.FetchByNames(files)
+	if err != nil {
+		log.Printf("failed to fetch processing documents: %v", err)
+		return &watcher.ProcessingDocuments{}
+	}
 
-	for _, file := range files {
-		obj, ok := mw.cacher.Get(file)
-		if !ok {
-			continue
-		}
+	return watcher.GroupProcessingDocuments(records)
+}
 
-		document := obj.(*watcher.Document)
-		switch document.QualityRecognized {
-		case -1:
-			procDocs.Processing = append(procDocs.Processing, file)
-		case 0:
-			procDocs.Unrecognized = append(procDocs.Unrecognized, file)
-		default:
-			procDocs.Done = append(procDocs.Done, file)
-		}
-	}
+func (mw *S3Minio) FetchQueueStats(_ context.Context) *watcher.QueueStats {
+	return mw.pipe.Stats()
+}
 
-	return procDocs
+// FetchDocumentStatus returns processing status of document or nil
+// if document has not been processed yet.
+func (mw *S3Minio) FetchDocumentStatus(
+	_ context.Context,
+	folderID, documentPath, version string,
+) (*watcher.DocumentStatus, error) {
+	record, _, err := mw.status.Store.Fetch(folderID, documentPath, version)
+	return record, err
 }
 
 func (mw *S3Minio) CleanProcessingDocuments(_ context.Context, files []string) error {
-	// TODO: Add RWLock to escape data race!
-	for _, file := range files {
-		mw.cacher.Delete(file)
-	}
+	mw.pipe.Cancel(func(job *pipeline.Job) bool {
+		return slices.Contains(files, job.Document.DocumentName)
+	})
 
-	return nil
+	return mw.status.Store.DeleteByNames(files)
 }
 
-func (mw *S3Minio) launchProcessEventLoop() {
-	wg := &sync.WaitGroup{}
-	for _, bucketName := range mw.config.WatchedDirectories {
-		wg.Add(1)
+// attachBucket launches listener of bucket, running state lock
+// must be held by caller.
+func (mw *S3Minio) attachBucket(bucketName string) {
+	cCtx, cancel := context.WithCancel(context.Background())
+	mw.bindBuckets.Store(bucketName, cancel)
+
+	mw.listeners.Add(1)
+	go func() {
+		defer func() {
+			mw.bindBuckets.Delete(bucketName)
+			mw.listeners.Done()
+		}()
+
+		mw.listenBucket(cCtx, bucketName)
+	}()
+}
+
+// listenBucket handles bucket notifications until context is done.
+func (mw *S3Minio) listenBucket(ctx context.Context, bucketName string) {
+	if mw.config.Backfill {
+		mw.listeners.Add(1)
 		go func() {
-			defer func() {
-				mw.bindBuckets.Delete(bucketName)
-				wg.Done()
-			}()
-
-			cCtx, cancel := context.WithCancel(context.Background())
-			mw.bindBuckets.Store(bucketName, cancel)
-
-			bind := mw.mc.ListenBucketNotification(cCtx, bucketName, prefix, suffix, eventsFilter)
-			for event := range bind {
-				if event.Err == nil {
-					mw.extractAndStoreDocument(cCtx, event)
-				}
-			}
+			defer mw.listeners.Done()
+			mw.backfillBucket(ctx, bucketName)
 		}()
 	}
 
-	wg.Wait()
+	bind := mw.mc.ListenBucketNotification(ctx, bucketName, prefix, suffix, eventsFilter)
+	for event := range bind {
+		if event.Err == nil {
+			mw.handleEvent(ctx, event)
+		}
+	}
 }
@@ -3,11 +3,81 @@ package watcher
 import (
 	"crypto/md5"
 	"fmt"
+	"strings"
 
 	"github.com/glaslos/ssdeep"
 	"github.com/google/uuid"
 )
 
+type Stage string
+
+const (
+	StageQueued      Stage = "queued"
+	StageDownloading Stage = "downloading"
+	StageOcr         Stage = "ocr"
+	StageEmbedding   Stage = "embedding"
+	StageStoring     Stage = "storing"
+	StageDone        Stage = "done"
+	StageFailed      Stage = "failed"
+)
+
+type QueueStats struct {
+	Workers        int             `json:"workers"`
+	QueueSize      int             `json:"queue_size"`
+	QueueDepth     int             `json:"queue_depth"`
+	ActiveJobs     int64           `json:"active_jobs"`
+	StagesInFlight map[Stage]int64 `json:"stages_in_flight"`
+}
+
+type DocumentStatus struct {
+	FolderID       string             `json:"folder_id"`
+	DocumentPath   string             `json:"document_path"`
+	DocumentName   string             `json:"document_name"`
+	Version        string             `json:"version"`
+	Stage          Stage              `json:"stage"`
+	Error          string             `json:"error"`
+	DocumentID     string             `json:"document_id"`
+	DocumentSSDEEP string             `json:"document_ssdeep"`
+	PagesCount     int                `json:"pages_count"`
+	Recognizer     string             `json:"recognizer"`
+	Quality        int32              `json:"quality"`
+	LowQuality     bool               `json:"low_quality"`
+	ChunksCount    int                `json:"chunks_count"`
+	UpdatedAt      string             `json:"updated_at"`
+	Transitions    []*StageTransition `json:"transitions"`
+}
+
+type StageTransition struct {
+	Stage      Stage  `json:"stage"`
+	Timestamp  string `json:"timestamp"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+type StageEvent struct {
+	FolderID     string `json:"folder_id"`
+	DocumentPath string `json:"document_path"`
+	DocumentName string `json:"document_name"`
+	Version      string `json:"version"`
+	Stage        Stage  `json:"stage"`
+	Error        string `json:"error,omitempty"`
+	Timestamp    string `json:"timestamp"`
+
+	// Document is a processed document available for in-process subscribers.
+	Document *Document `json:"-"`
+}
+
+type FailedDocument struct {
+	JobID        string `json:"job_id"`
+	FolderID     string `json:"folder_id"`
+	DocumentPath string `json:"document_path"`
+	DocumentName string `json:"document_name"`
+	Stage        Stage  `json:"stage"`
+	Attempts     int    `json:"attempts"`
+	LastError    string `json:"last_error"`
+	FailedAt     string `json:"failed_at"`
+}
+
 type ProcessingDocuments struct {
 	Done         []string `json:"done"`
 	Processing   []string `json:"processing"`
@@ -31,14 +101,26 @@ type Document struct {
 	DocumentModified    string        `json:"document_modified"`
 	QualityRecognized   int32         `json:"quality_recognition"`
 	OcrMetadata         *OcrMetadata  `json:"ocr_metadata"`
+	Pages               []*Page       `json:"pages,omitempty"`
+	EmbeddingsModel     string        `json:"embeddings_model"`
+	EmbeddingsDimension int           `json:"embeddings_dimension"`
 	Embeddings          []*Embeddings `json:"embeddings"`
 }
 
+// Page is a text of recognized page and its byte offset into document content.
+type Page struct {
+	Number int    `json:"number"`
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+}
+
 type OcrMetadata struct {
 	JobId      string       `json:"job_id"`
 	Text       string       `json:"text"`
 	PagesCount int          `json:"pages_count"`
 	DocType    string       `json:"doc_type"`
+	Recognizer string       `json:"recognizer"`
+	LowQuality bool         `json:"low_quality"`
 	Artifacts  []*Artifacts `json:"artifacts"`
 }
 
@@ -53,10 +135,51 @@ type Artifacts struct {
 	} `json:"group_values"`
 }
 
+// VectorRecord is a chunk vector stored into separate vectors index and
+// linked to its parent document by parent id.
+type VectorRecord struct {
+	FolderID        string    `json:"folder_id"`
+	DocumentID      string    `json:"document_id"`
+	ParentID        string    `json:"parent_id"`
+	DocumentPath    string    `json:"document_path"`
+	DocumentName    string    `json:"document_name"`
+	ChunkIndex      int       `json:"chunk_index"`
+	PageNumber      int       `json:"page_number,omitempty"`
+	TextChunk       string    `json:"text_chunk"`
+	EmbeddingsModel string    `json:"embeddings_model"`
+	Vector          []float64 `json:"vector"`
+}
+
 type Embeddings struct {
-	ChunkID   string    `json:"chunk_id"`
-	TextChunk string    `json:"text_chunk"`
-	Vector    []float64 `json:"vector"`
+	ChunkID    string    `json:"chunk_id"`
+	TextChunk  string    `json:"text_chunk"`
+	PageNumber int       `json:"page_number,omitempty"`
+	Vector     []float64 `json:"vector"`
+}
+
+// GroupProcessingDocuments groups document names by latest processing stage.
+func GroupProcessingDocuments(records []*DocumentStatus) *ProcessingDocuments {
+	latest := make(map[string]*DocumentStatus)
+	for _, record := range records {
+		stored, ok := latest[record.DocumentName]
+		if !ok || stored.UpdatedAt < record.UpdatedAt {
+			latest[record.DocumentName] = record
+		}
+	}
+
+	procDocs := &ProcessingDocuments{}
+	for name, record := range latest {
+		switch record.Stage {
+		case StageDone:
+			procDocs.Done = append(procDocs.Done, name)
+		case StageFailed:
+			procDocs.Unrecognized = append(procDocs.Unrecognized, name)
+		default:
+			procDocs.Processing = append(procDocs.Processing, name)
+		}
+	}
+
+	return procDocs
 }
 
 func DefaultOcr() *OcrMetadata {
@@ -89,6 +212,12 @@ func (d *Document) SetEmbeddings(embeddings []*Embeddings) {
 	d.Embeddings = embeddings
 }
 
+// SetEmbeddingsModel records model and dimension of document vectors.
+func (d *Document) SetEmbeddingsModel(model string, dimension int) {
+	d.EmbeddingsModel = model
+	d.EmbeddingsDimension = dimension
+}
+
 func (d *Document) SetQuality(quality int32) {
 	d.QualityRecognized = quality
 }
@@ -125,14 +254,85 @@ func (d *Document) GetArtifacts() []*Artifacts {
 	return d.OcrMetadata.Artifacts
 }
 
-func (d *Document) AppendContentVector(text string, tokens []float64) {
+func (d *Document) AppendContentVector(text string, tokens []float64) *Embeddings {
 	embeddings := &Embeddings{
-		ChunkID:   uuid.New().String(),
+		ChunkID:   ChunkID(d.DocumentID, len(d.Embeddings)),
 		Vector:    tokens,
 		TextChunk: text,
 	}
 
 	d.Embeddings = append(d.Embeddings, embeddings)
+	return embeddings
+}
+
+// ChunkID returns the same id of chunk by parent document id and chunk
+// index, so chunks stored again replace previously stored ones.
+func ChunkID(documentID string, index int) string {
+	name := fmt.Sprintf("%s/%d", documentID, index)
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
+// WithoutEmbeddings returns document copy without chunks vectors.
+func (d *Document) WithoutEmbeddings() *Document {
+	document := *d
+	document.Embeddings = make([]*Embeddings, 0)
+	return &document
+}
+
+// VectorRecords returns records of document chunks vectors.
+func (d *Document) VectorRecords() []*VectorRecord {
+	records := make([]*VectorRecord, 0, len(d.Embeddings))
+	for index, embedding := range d.Embeddings {
+		records = append(records, &VectorRecord{
+			FolderID:        d.FolderID,
+			DocumentID:      embedding.ChunkID,
+			ParentID:        d.DocumentID,
+			DocumentPath:    d.DocumentPath,
+			DocumentName:    d.DocumentName,
+			ChunkIndex:      index,
+			PageNumber:      embedding.PageNumber,
+			TextChunk:       embedding.TextChunk,
+			EmbeddingsModel: d.EmbeddingsModel,
+			Vector:          embedding.Vector,
+		})
+	}
+
+	return records
+}
+
+// SetPages sets content of document joined from recognized pages texts.
+func (d *Document) SetPages(texts []string) {
+	var builder strings.Builder
+
+	d.Pages = make([]*Page, 0, len(texts))
+	for index, text := range texts {
+		if index > 0 {
+			builder.WriteByte('\n')
+		}
+
+		d.Pages = append(d.Pages, &Page{
+			Number: index + 1,
+			Offset: builder.Len(),
+			Text:   text,
+		})
+		builder.WriteString(text)
+	}
+
+	d.Content = builder.String()
+}
+
+// PageNumberAt returns number of page containing content byte offset
+// or 0 if document has not been recognized by pages.
+func (d *Document) PageNumberAt(offset int) int {
+	number := 0
+	for _, page := range d.Pages {
+		if page.Offset > offset {
+			break
+		}
+		number = page.Number
+	}
+
+	return number
 }
 
 func (d *Document) ComputeMd5Hash() {
@@ -1,6 +1,16 @@
 package watcher
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrWatchersRunning is returned when running watchers are launched again.
+	ErrWatchersRunning = errors.New("watchers are running already")
+	// ErrWatchersStopped is returned when watchers have not been launched.
+	ErrWatchersStopped = errors.New("watchers are not running")
+)
 
 type Service struct {
 	Watcher IWatcher
@@ -10,11 +20,14 @@ type IWatcher interface {
 	IDirectories
 	ILaunch
 	IProcessing
+	IBackfill
+	IReembed
+	IFailed
 }
 
 type ILaunch interface {
-	RunWatchers(ctx context.Context)
-	TerminateWatchers(ctx context.Context)
+	RunWatchers(ctx context.Context) error
+	TerminateWatchers(ctx context.Context) error
 }
 
 type IDirectories interface {
@@ -26,4 +39,22 @@ type IDirectories interface {
 type IProcessing interface {
 	CleanProcessingDocuments(ctx context.Context, files []string) error
 	FetchProcessingDocuments(ctx context.Context, files []string) *ProcessingDocuments
+	FetchQueueStats(ctx context.Context) *QueueStats
+	FetchDocumentStatus(ctx context.Context, folderID, documentPath, version string) (*DocumentStatus, error)
+}
+
+type IBackfill interface {
+	BackfillDirectory(ctx context.Context, dir string) error
+	FetchBackfillProgress(ctx context.Context) []*BackfillProgress
+}
+
+type IReembed interface {
+	ReembedDirectory(ctx context.Context, dir string, options *ReembedOptions) error
+	FetchReembedProgress(ctx context.Context) []*ReembedProgress
+}
+
+type IFailed interface {
+	FetchFailedDocuments(ctx context.Context) ([]*FailedDocument, error)
+	RequeueFailedDocuments(ctx context.Context, jobIDs []string, all bool) error
+	CleanFailedDocuments(ctx context.Context, jobIDs []string, all bool) error
 }
@@ -0,0 +1,189 @@
+package watcher
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+type ReembedOptions struct {
+	// TargetFolder is an index to store new vectors for zero downtime
+	// cutover, documents are replaced in their own index if it is empty.
+	TargetFolder string `json:"target_folder"`
+	// Force reembeds documents already embedded by current model.
+	Force      bool `json:"force"`
+	Rate       int  `json:"rate"`
+	MaxPending int  `json:"max_pending"`
+}
+
+type ReembedProgress struct {
+	Directory    string `json:"directory"`
+	TargetFolder string `json:"target_folder"`
+	Model        string `json:"model"`
+	Listed       int    `json:"listed"`
+	Processed    int    `json:"processed"`
+	Skipped      int    `json:"skipped"`
+	Failed       int    `json:"failed"`
+	Done         bool   `json:"done"`
+	StartedAt    string `json:"started_at"`
+	FinishedAt   string `json:"finished_at"`
+}
+
+// ReembedTracker stores progress of directories reembedding
+// which may be fetched concurrently by http server.
+type ReembedTracker struct {
+	mu       sync.RWMutex
+	progress map[string]*ReembedProgress
+}
+
+func NewReembedTracker() *ReembedTracker {
+	return &ReembedTracker{
+		progress: make(map[string]*ReembedProgress),
+	}
+}
+
+// Begin starts progress of directory and returns false if directory
+// is being reembedded already.
+func (rt *ReembedTracker) Begin(dir string, options *ReembedOptions, model string) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if progress, ok := rt.progress[dir]; ok && !progress.Done {
+		return false
+	}
+
+	rt.progress[dir] = &ReembedProgress{
+		Directory:    dir,
+		TargetFolder: options.TargetFolder,
+		Model:        model,
+		StartedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+	return true
+}
+
+func (rt *ReembedTracker) Update(dir string, fn func(progress *ReembedProgress)) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if progress, ok := rt.progress[dir]; ok {
+		fn(progress)
+	}
+}
+
+func (rt *ReembedTracker) Finish(dir string) {
+	rt.Update(dir, func(progress *ReembedProgress) {
+		progress.Done = true
+		progress.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	})
+}
+
+func (rt *ReembedTracker) Snapshot() []*ReembedProgress {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	snapshot := make([]*ReembedProgress, 0, len(rt.progress))
+	for _, progress := range rt.progress {
+		progressCopy := *progress
+		snapshot = append(snapshot, &progressCopy)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Directory < snapshot[j].Directory
+	})
+
+	return snapshot
+}
+
+// Reembed enqueues indexed objects of directory which vectors have been
+// computed by another model. Enqueued objects are throttled by rate per
+// minute and count of pending objects, so reembedding does not starve
+// documents caught by watchers. Progress must be begun by caller.
+func Reembed(
+	ctx context.Context,
+	dir string,
+	objects []*IndexedObject,
+	options *ReembedOptions,
+	model string,
+	tracker *ReembedTracker,
+	enqueue func(object *IndexedObject, onDone func(err error)) error,
+) {
+	log.Printf("launching reembedding of directory %s by model %s", dir, model)
+	defer tracker.Finish(dir)
+
+	tracker.Update(dir, func(progress *ReembedProgress) {
+		progress.Listed = len(objects)
+	})
+
+	var rateCh <-chan time.Time
+	if options.Rate > 0 {
+		ticker := time.NewTicker(time.Minute / time.Duration(options.Rate))
+		defer ticker.Stop()
+		rateCh = ticker.C
+	}
+
+	pending := make(chan struct{}, max(options.MaxPending, 1))
+
+	wg := &sync.WaitGroup{}
+	onDone := func(err error) {
+		defer wg.Done()
+		<-pending
+		tracker.Update(dir, func(progress *ReembedProgress) {
+			if err != nil {
+				progress.Failed++
+			} else {
+				progress.Processed++
+			}
+		})
+	}
+
+	for _, object := range objects {
+		if !options.Force && isReembedded(object, options.TargetFolder, model) {
+			tracker.Update(dir, func(progress *ReembedProgress) {
+				progress.Skipped++
+			})
+			continue
+		}
+
+		if rateCh != nil {
+			select {
+			case <-rateCh:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case pending <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		wg.Add(1)
+		if err := enqueue(object, onDone); err != nil {
+			log.Printf("failed to enqueue file %s to reembed: %v", object.ObjectPath, err)
+			onDone(err)
+		}
+	}
+
+	// Jobs interrupted by shutdown may be never finished.
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		log.Printf("reembedding of directory %s has been finished", dir)
+	case <-ctx.Done():
+		log.Printf("reembedding of directory %s has been interrupted", dir)
+	}
+}
+
+// isReembedded checks that object has been stored into target folder
+// or into its own folder with vectors of current model.
+func isReembedded(object *IndexedObject, targetFolder, model string) bool {
+	return object.StoredFolder == targetFolder && object.EmbeddingsModel == model
+}
@@ -0,0 +1,13 @@
+package webhooks
+
+import (
+	"time"
+
+	"doc-watcher/internal/retry"
+)
+
+type Config struct {
+	Timeout       time.Duration
+	Retry         retry.Policy
+	Subscriptions []*Subscription
+}
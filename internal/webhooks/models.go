@@ -0,0 +1,46 @@
+package webhooks
+
+import (
+	"slices"
+
+	"doc-watcher/internal/watcher"
+)
+
+type Subscription struct {
+	ID      string          `json:"id"`
+	URL     string          `json:"url"`
+	Events  []watcher.Stage `json:"events"`
+	Buckets []string        `json:"buckets"`
+	Secret  string          `json:"secret,omitempty"`
+}
+
+type Payload struct {
+	Event     watcher.Stage     `json:"event"`
+	Timestamp string            `json:"timestamp"`
+	Error     string            `json:"error,omitempty"`
+	Document  *watcher.Document `json:"document"`
+}
+
+// Matches returns true if subscription listens event. Subscription without
+// events and buckets listens all finished documents of all buckets.
+func (s *Subscription) Matches(event *watcher.StageEvent) bool {
+	if len(s.Events) > 0 && !slices.Contains(s.Events, event.Stage) {
+		return false
+	}
+
+	if len(s.Buckets) > 0 && !slices.Contains(s.Buckets, event.FolderID) {
+		return false
+	}
+
+	return true
+}
+
+// Masked returns copy of subscription without signing secret.
+func (s *Subscription) Masked() *Subscription {
+	masked := *s
+	if len(masked.Secret) > 0 {
+		masked.Secret = "******"
+	}
+
+	return &masked
+}
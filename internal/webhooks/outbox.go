@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"doc-watcher/internal/watcher"
+	"github.com/google/uuid"
+)
+
+const (
+	outboxBucket       = "webhooks-outbox"
+	outboxPollInterval = time.Second
+)
+
+// delivery is a webhook persisted into outbox until it has been sent
+// or all its attempts have failed.
+type delivery struct {
+	ID             string   `json:"id"`
+	SubscriptionID string   `json:"subscription_id"`
+	Payload        *Payload `json:"payload"`
+	Attempts       int      `json:"attempts"`
+	NextAttemptAt  int64    `json:"next_attempt_at"`
+}
+
+// enqueue persists deliveries of finished document event for each matched
+// subscription. It is called synchronously by events broker, so events
+// are not dropped while deliveries are being retried.
+func (s *Service) enqueue(event *watcher.StageEvent) {
+	if !slices.Contains(publishedEvents, event.Stage) {
+		return
+	}
+
+	subscriptions := s.matchSubscriptions(event)
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	payload := buildPayload(event)
+	for _, sub := range subscriptions {
+		now := time.Now().UnixNano()
+		item := &delivery{
+			// Ids are ordered by enqueue time to send deliveries in order.
+			ID:             fmt.Sprintf("%020d-%s", now, uuid.New().String()),
+			SubscriptionID: sub.ID,
+			Payload:        payload,
+			NextAttemptAt:  now,
+		}
+
+		if err := s.store.Put(outboxBucket, item.ID, item); err != nil {
+			log.Printf("failed to store webhook to %s: %v", sub.URL, err)
+		}
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch sends deliveries which next attempt is due with bounded
+// count of concurrent requests.
+func (s *Service) dispatch(ctx context.Context, wg *sync.WaitGroup) {
+	deliveries, err := s.pending()
+	if err != nil {
+		log.Printf("failed to load webhooks outbox: %v", err)
+		return
+	}
+
+	for _, item := range deliveries {
+		select {
+		case s.deliveries <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		s.inFlight.Store(item.ID, true)
+		wg.Add(1)
+		go func() {
+			defer func() {
+				s.inFlight.Delete(item.ID)
+				<-s.deliveries
+				wg.Done()
+			}()
+
+			s.deliver(ctx, item)
+		}()
+	}
+}
+
+func (s *Service) pending() ([]*delivery, error) {
+	now := time.Now().UnixNano()
+	deliveries := make([]*delivery, 0)
+	err := s.store.ForEach(outboxBucket, func(key string, data []byte) error {
+		if _, ok := s.inFlight.Load(key); ok {
+			return nil
+		}
+
+		item := &delivery{}
+		if err := json.Unmarshal(data, item); err != nil {
+			log.Printf("failed to unmarshal webhook %s: %v", key, err)
+			return nil
+		}
+
+		if item.NextAttemptAt <= now {
+			deliveries = append(deliveries, item)
+		}
+		return nil
+	})
+
+	return deliveries, err
+}
+
+// deliver sends delivery once and schedules next attempt by retry policy
+// if it has failed. Delivery interrupted by shutdown is sent after restart.
+func (s *Service) deliver(ctx context.Context, item *delivery) {
+	sub, ok := s.subscription(item.SubscriptionID)
+	if !ok {
+		s.complete(item)
+		return
+	}
+
+	err := s.send(ctx, sub, item.Payload)
+	if err == nil {
+		s.complete(item)
+		return
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	item.Attempts++
+	policy := &s.config.Retry
+	if item.Attempts >= policy.MaxAttempts || !policy.IsRetryable(err) {
+		log.Printf("failed to send webhook to %s: %v", sub.URL, err)
+		s.complete(item)
+		return
+	}
+
+	item.NextAttemptAt = time.Now().Add(policy.Backoff(item.Attempts)).UnixNano()
+	if err = s.store.Put(outboxBucket, item.ID, item); err != nil {
+		log.Printf("failed to store webhook to %s: %v", sub.URL, err)
+	}
+}
+
+func (s *Service) complete(item *delivery) {
+	if err := s.store.Delete(outboxBucket, item.ID); err != nil {
+		log.Printf("failed to delete webhook %s from outbox: %v", item.ID, err)
+	}
+}
@@ -0,0 +1,251 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"doc-watcher/internal/events"
+	"doc-watcher/internal/sender"
+	"doc-watcher/internal/storage"
+	"doc-watcher/internal/watcher"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	SignatureHeader    = "X-Doc-Watcher-Signature"
+	EventHeader        = "X-Doc-Watcher-Event"
+	subscriptionBucket = "webhooks"
+	deliveryLimit      = 8
+)
+
+var ErrNotFound = errors.New("there is no such webhook")
+
+// publishedEvents are stages of documents which webhooks are sent for.
+var publishedEvents = []watcher.Stage{watcher.StageDone, watcher.StageFailed}
+
+// Service sends webhooks to subscribers when document processing
+// has been finished or failed.
+type Service struct {
+	config *Config
+	events *events.Broker
+	store  *storage.Service
+
+	client *http.Client
+
+	mu            sync.RWMutex
+	subscriptions map[string]*Subscription
+	deliveries    chan struct{}
+	inFlight      sync.Map
+	notify        chan struct{}
+}
+
+func New(config *Config, eventsBroker *events.Broker, store *storage.Service) *Service {
+	service := &Service{
+		config:        config,
+		events:        eventsBroker,
+		store:         store,
+		client:        &http.Client{Timeout: config.Timeout * time.Second},
+		subscriptions: make(map[string]*Subscription),
+		deliveries:    make(chan struct{}, deliveryLimit),
+		notify:        make(chan struct{}, 1),
+	}
+
+	for _, sub := range config.Subscriptions {
+		if err := validateEvents(sub.Events); err != nil {
+			log.Fatalln("invalid webhook of config: ", err)
+		}
+
+		// Id is derived from whole subscription, so subscriptions with the
+		// same url and different filters do not replace each other.
+		if len(sub.ID) == 0 {
+			subData, _ := json.Marshal(sub)
+			sub.ID = uuid.NewSHA1(uuid.NameSpaceURL, subData).String()
+		}
+		service.subscriptions[sub.ID] = sub
+	}
+
+	err := store.ForEach(subscriptionBucket, func(key string, data []byte) error {
+		sub := &Subscription{}
+		if err := json.Unmarshal(data, sub); err != nil {
+			log.Printf("failed to unmarshal webhook %s: %v", key, err)
+			return nil
+		}
+
+		service.subscriptions[sub.ID] = sub
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("failed to load webhooks: %v", err)
+	}
+
+	eventsBroker.Handle(service.enqueue)
+	return service
+}
+
+// Run sends webhooks persisted into outbox until passed context is done
+// and waits for sending ones. Webhooks which have not been sent yet
+// are kept into outbox to be sent after restart.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	for {
+		s.dispatch(ctx, wg)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) List() []*Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscriptions := make([]*Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		subscriptions = append(subscriptions, sub.Masked())
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].URL < subscriptions[j].URL
+	})
+
+	return subscriptions
+}
+
+func (s *Service) Create(sub *Subscription) (*Subscription, error) {
+	if _, err := url.ParseRequestURI(sub.URL); err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	if err := validateEvents(sub.Events); err != nil {
+		return nil, err
+	}
+
+	sub.ID = uuid.New().String()
+	if err := s.store.Put(subscriptionBucket, sub.ID, sub); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions[sub.ID] = sub
+	return sub.Masked(), nil
+}
+
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return ErrNotFound
+	}
+
+	if err := s.store.Delete(subscriptionBucket, id); err != nil {
+		return err
+	}
+
+	delete(s.subscriptions, id)
+	return nil
+}
+
+// validateEvents rejects events which webhooks are never sent for.
+func validateEvents(events []watcher.Stage) error {
+	for _, event := range events {
+		if !slices.Contains(publishedEvents, event) {
+			return fmt.Errorf("unknown webhook event %s, expected one of %v", event, publishedEvents)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) subscription(id string) (*Subscription, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subscriptions[id]
+	return sub, ok
+}
+
+func (s *Service) matchSubscriptions(event *watcher.StageEvent) []*Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := make([]*Subscription, 0)
+	for _, sub := range s.subscriptions {
+		if sub.Matches(event) {
+			matched = append(matched, sub)
+		}
+	}
+
+	return matched
+}
+
+func (s *Service) send(ctx context.Context, sub *Subscription, payload *Payload) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed while marshaling webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(EventHeader, string(payload.Event))
+	if len(sub.Secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+signPayload(sub.Secret, jsonData))
+	}
+
+	_, err = sender.SendRequest(s.client, req)
+	return err
+}
+
+func buildPayload(event *watcher.StageEvent) *Payload {
+	payload := &Payload{
+		Event:     event.Stage,
+		Timestamp: event.Timestamp,
+		Error:     event.Error,
+	}
+
+	if event.Document != nil {
+		document := *event.Document
+		document.Content = ""
+		document.Pages = nil
+		document.Embeddings = nil
+		payload.Document = &document
+	}
+
+	return payload
+}
+
+func signPayload(secret string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}